@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/deduce"
+	"github.com/levilutz/minesweeper/pkg/probability"
+	"github.com/levilutz/minesweeper/pkg/solver"
+)
+
+// Render the mine probability of each unknown tile as a percentage.
+func renderProbabilities(v board.View, probs [][]float64) string {
+	out := ""
+	for y := v.GetHeight() - 1; y >= 0; y-- {
+		for x := 0; x < v.GetWidth(); x++ {
+			n := v.GetNumNeighbors(x, y)
+			switch {
+			case v.HasFlag(x, y):
+				out += "    F"
+			case v.Revealed(x, y) && n > 0:
+				out += fmt.Sprintf("%5d", n)
+			case v.Revealed(x, y):
+				out += "    ."
+			default:
+				out += fmt.Sprintf("%4.0f%%", probs[x][y]*100)
+			}
+		}
+		out += "\n"
+	}
+	return out
+}
+
+func main() {
+	numMines := flag.Int("mines", -1, "total number of mines, including flagged ones")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: analyze -mines N [file]")
+		fmt.Fprintln(os.Stderr, "reads a position in grid notation from file, or stdin")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *numMines < 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var data []byte
+	var err error
+	if flag.NArg() > 0 {
+		data, err = os.ReadFile(flag.Arg(0))
+	} else {
+		data, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	p, err := board.ParsePosition(string(data), *numMines)
+	if err != nil {
+		fmt.Printf("failed to parse position: %s\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("solver:")
+	for _, move := range solver.Moves(p) {
+		fmt.Printf("  %s\n", move)
+	}
+	fmt.Println("deduce:")
+	for _, move := range deduce.Moves(p, 100000) {
+		fmt.Printf("  %s\n", move)
+	}
+
+	probs, err := probability.Compute(p)
+	if err != nil {
+		fmt.Printf("failed to compute probabilities: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("mine probabilities:")
+	fmt.Print(renderProbabilities(p, probs))
+}
@@ -1,77 +1,133 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"math/rand"
+	"os"
 	"time"
 
 	"github.com/levilutz/minesweeper/pkg/board"
 	"github.com/levilutz/minesweeper/pkg/deduce"
-	"github.com/levilutz/minesweeper/pkg/solver"
+	"github.com/levilutz/minesweeper/pkg/game"
+	"github.com/levilutz/minesweeper/pkg/guess"
+	"github.com/levilutz/minesweeper/pkg/replay"
 	"github.com/levilutz/minesweeper/pkg/textrender"
 )
 
-func ViewOne() {
-	boardSize := 16
-	numMines := 40
+// Play a game, recording each move. If explain is set, print why each deduced
+// move was made.
+func ViewOne(g *game.Game, rec *replay.Recorder, explain bool) {
 	delay := time.Millisecond * 50
+	b := g.Board()
 
-	var b *board.Board
-	for {
-		b = board.NewBoard(boardSize)
-		b.SpawnMines(numMines)
-		if !b.HasMine(0, 0) {
-			break
-		}
-	}
-
+	guesses := 0
 	for {
 		fmt.Println(textrender.RenderBoard(b))
-		tookAction := deduce.Pass(b, 100000)
-		if b.HasRevealedMines() {
-			fmt.Println(textrender.RenderBoard(b))
-			fmt.Println("solver hit a mine")
-			break
-		} else if b.Complete() {
-			fmt.Println(textrender.RenderBoard(b))
-			fmt.Println("solver won!")
-			break
-		} else if !tookAction {
+		took := g.Duration().Round(time.Millisecond)
+		switch g.State() {
+		case game.Lost:
+			fmt.Printf("solver hit a mine after %d guesses in %s\n", guesses, took)
+			return
+		case game.Won:
+			fmt.Printf("solver won with %d guesses in %s!\n", guesses, took)
+			return
+		}
+		var move *board.Move
+		if moves := deduce.Moves(b.PlayerView(), 100000); len(moves) > 0 {
+			move = &moves[0]
+			if explain {
+				fmt.Printf("%s (%d, %d) because %s\n", pastTense(move.Kind), move.Tile.X, move.Tile.Y, move.Reason)
+			}
+		} else if gs, err := guess.Best(b.PlayerView()); err == nil {
+			m := gs.Move()
+			move = &m
+			guesses += 1
+			fmt.Printf("solver guessed %s\n", gs)
+		}
+		if move == nil {
 			fmt.Println("solver stuck")
 			break
 		}
-		time.Sleep(delay)
+		if _, err := rec.Apply(*move); err != nil {
+			panic(err)
+		}
+		if !g.State().Over() {
+			time.Sleep(delay)
+		}
 	}
 }
 
-// Run numRounds tests of the solver, return the number of successes.
-func TestRounds(numRounds int) int {
-	boardSize := 16
+// Describe a move kind as something done.
+func pastTense(k board.MoveKind) string {
+	switch k {
+	case board.MoveReveal:
+		return "revealed"
+	case board.MoveFlag:
+		return "flagged"
+	case board.MoveUnflag:
+		return "unflagged"
+	case board.MoveChord:
+		return "chorded"
+	}
+	return k.String()
+}
+
+func main() {
+	boardWidth := 16
+	boardHeight := 16
 	numMines := 40
-	allowedSteps := boardSize * boardSize * 2
 
-	wins := 0
-	for round := 0; round < numRounds; round++ {
-		b := board.NewBoard(boardSize)
-		b.SpawnMines(numMines)
-		if b.HasMine(0, 0) {
-			round--
-			continue
-		}
-		var tookAction bool
-		for i := 0; i < allowedSteps; i++ {
-			tookAction = solver.Pass(b)
-			if b.Complete() && !tookAction {
-				break
-			}
+	seed := flag.Int64("seed", 0, "seed for mine generation (random if 0)")
+	firstClick := flag.String("first-click", "safe", "first reveal protection: none, safe or opening")
+	load := flag.String("load", "", "file to load a board position from, instead of generating one")
+	record := flag.String("record", "", "file to save a replay of the game to")
+	explain := flag.Bool("explain", false, "print the reasoning behind each deduced move")
+	flag.Parse()
+	policy, err := board.ParseFirstClick(*firstClick)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	if *load != "" {
+		data, err := os.ReadFile(*load)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
 		}
-		if b.Complete() {
-			wins += 1
+		b, err := board.Unmarshal(data)
+		if err != nil {
+			fmt.Printf("failed to load %s: %s\n", *load, err)
+			os.Exit(1)
 		}
+		play(b, 0, *record, *explain)
+		return
 	}
-	return wins
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	fmt.Printf("seed: %d\n", *seed)
+	b := board.NewBoard(boardWidth, boardHeight)
+	rng := rand.New(rand.NewSource(*seed))
+	if err := b.SpawnMinesOnReveal(numMines, rng, policy); err != nil {
+		panic(err)
+	}
+	play(b, *seed, *record, *explain)
+	fmt.Printf("seed: %d\n", *seed)
 }
 
-func main() {
-	// fmt.Printf("%d / 10000", TestRounds(10000))
-	ViewOne()
+// Play a game on the board, saving a replay of it if a path is given.
+func play(b *board.Board, seed int64, record string, explain bool) {
+	g := game.New(b)
+	rec := replay.NewRecorder(g, seed)
+	ViewOne(g, rec, explain)
+	if record != "" {
+		if err := replay.Save(record, rec.Replay()); err != nil {
+			fmt.Printf("failed to save replay: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("saved replay to %s\n", record)
+	}
 }
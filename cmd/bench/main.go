@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/levilutz/minesweeper/pkg/bench"
+)
+
+// Parse a comma-separated list of sizes like "9x9,30x16".
+func parseSizes(s string) ([][2]int, error) {
+	out := [][2]int{}
+	for _, size := range strings.Split(s, ",") {
+		dims := strings.Split(size, "x")
+		if len(dims) != 2 {
+			return nil, fmt.Errorf("invalid size: %s", size)
+		}
+		width, err := strconv.Atoi(dims[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid size: %s", size)
+		}
+		height, err := strconv.Atoi(dims[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid size: %s", size)
+		}
+		out = append(out, [2]int{width, height})
+	}
+	return out, nil
+}
+
+// Parse a comma-separated list of densities like "0.12,0.2".
+func parseDensities(s string) ([]float64, error) {
+	out := []float64{}
+	for _, density := range strings.Split(s, ",") {
+		d, err := strconv.ParseFloat(density, 64)
+		if err != nil || d < 0 || d > 1 {
+			return nil, fmt.Errorf("invalid density: %s", density)
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// Parse a comma-separated list of strategy names.
+func parseStrategies(s string) ([]bench.Strategy, error) {
+	out := []bench.Strategy{}
+	for _, name := range strings.Split(s, ",") {
+		strategy, ok := bench.Strategies[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown solver: %s", name)
+		}
+		out = append(out, strategy)
+	}
+	return out, nil
+}
+
+func writeText(summaries []bench.Summary) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "solver\tboard\tgames\twin rate\t95% ci\tguesses\tmoves\tsteps\tgame time\twall")
+	for _, s := range summaries {
+		fmt.Fprintf(
+			w,
+			"%s\t%dx%d/%d\t%d\t%.1f%%\t%.1f-%.1f%%\t%.2f\t%.1f\t%.0f\t%.2fs\t%.2fs\n",
+			s.Strategy, s.Width, s.Height, s.Mines, s.Games,
+			s.WinRate*100, s.WinRateLow*100, s.WinRateHigh*100,
+			s.GuessesPerGame, s.MovesPerGame, s.StepsPerGame, s.GameSeconds, s.WallSeconds,
+		)
+	}
+	w.Flush()
+}
+
+func writeCSV(summaries []bench.Summary) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{
+		"strategy", "width", "height", "mines", "games", "wins", "winRate",
+		"winRateLow", "winRateHigh", "guessesPerGame", "movesPerGame",
+		"stepsPerGame", "gameSeconds", "wallSeconds",
+	})
+	f := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	for _, s := range summaries {
+		w.Write([]string{
+			s.Strategy, strconv.Itoa(s.Width), strconv.Itoa(s.Height),
+			strconv.Itoa(s.Mines), strconv.Itoa(s.Games), strconv.Itoa(s.Wins),
+			f(s.WinRate), f(s.WinRateLow), f(s.WinRateHigh), f(s.GuessesPerGame),
+			f(s.MovesPerGame), f(s.StepsPerGame), f(s.GameSeconds), f(s.WallSeconds),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func main() {
+	games := flag.Int("games", 100, "number of games per board and solver")
+	sizes := flag.String("sizes", "9x9,16x16,30x16", "comma-separated board sizes")
+	densities := flag.String("densities", "0.15", "comma-separated mine densities")
+	solvers := flag.String("solvers", "solver,deduce", "comma-separated solvers: solver, deduce")
+	format := flag.String("format", "text", "output format: text, json or csv")
+	seed := flag.Int64("seed", 0, "seed for the benchmark (random if 0)")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of games to play in parallel")
+	flag.Parse()
+
+	fail := func(err error) {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	boardSizes, err := parseSizes(*sizes)
+	if err != nil {
+		fail(err)
+	}
+	boardDensities, err := parseDensities(*densities)
+	if err != nil {
+		fail(err)
+	}
+	strategies, err := parseStrategies(*solvers)
+	if err != nil {
+		fail(err)
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	fmt.Fprintf(os.Stderr, "seed: %d\n", *seed)
+
+	// Every solver plays the same boards, so results are directly comparable.
+	rng := rand.New(rand.NewSource(*seed))
+	summaries := []bench.Summary{}
+	for _, size := range boardSizes {
+		for _, density := range boardDensities {
+			cfg := bench.ConfigFromDensity(size[0], size[1], density)
+			seeds := make([]int64, *games)
+			for i := range seeds {
+				seeds[i] = rng.Int63()
+			}
+			for _, strategy := range strategies {
+				summary, err := bench.Run(cfg, strategy, seeds, *workers)
+				if err != nil {
+					fail(fmt.Errorf("%s on %s: %w", strategy.Name, cfg, err))
+				}
+				summaries = append(summaries, summary)
+			}
+		}
+	}
+
+	switch *format {
+	case "text":
+		writeText(summaries)
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(summaries); err != nil {
+			fail(err)
+		}
+	case "csv":
+		if err := writeCSV(summaries); err != nil {
+			fail(err)
+		}
+	default:
+		fail(fmt.Errorf("unknown format: %s", *format))
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/generate"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+func main() {
+	width := flag.Int("width", 16, "board width")
+	height := flag.Int("height", 16, "board height")
+	numMines := flag.Int("mines", 40, "number of mines")
+	firstX := flag.Int("x", 0, "x coordinate of the first click")
+	firstY := flag.Int("y", 0, "y coordinate of the first click")
+	count := flag.Int("count", 1, "number of boards to generate")
+	seed := flag.Int64("seed", 0, "seed for the batch (random if 0)")
+	outDir := flag.String("out", ".", "directory to write boards to")
+	flag.Parse()
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	fmt.Printf("seed: %d\n", *seed)
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	// Each board gets its own seed, so it can be regenerated individually.
+	rng := rand.New(rand.NewSource(*seed))
+	first := util.Vec{X: *firstX, Y: *firstY}
+	for i := 0; i < *count; i++ {
+		boardSeed := rng.Int63()
+		b, err := generate.NoGuess(*width, *height, *numMines, first, boardSeed)
+		if err != nil {
+			fmt.Printf("board %d (seed %d): %s\n", i, boardSeed, err)
+			continue
+		}
+		data, err := board.Marshal(b)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		header := fmt.Sprintf("# seed %d, first click (%d, %d)\n", boardSeed, first.X, first.Y)
+		path := filepath.Join(*outDir, fmt.Sprintf("board-%d.txt", boardSeed))
+		if err := os.WriteFile(path, append([]byte(header), data...), 0o644); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("wrote %s\n", path)
+	}
+}
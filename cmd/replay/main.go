@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/levilutz/minesweeper/pkg/replay"
+	"github.com/levilutz/minesweeper/pkg/textrender"
+)
+
+// Read lines from stdin, closing the channel at the end of input.
+func readLines(lines chan<- string) {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		lines <- strings.TrimSpace(scanner.Text())
+	}
+	close(lines)
+}
+
+func main() {
+	delay := flag.Duration("delay", 500*time.Millisecond, "time between steps while playing")
+	paused := flag.Bool("paused", false, "start paused")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <replay>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	r, err := replay.Load(flag.Arg(0))
+	if err != nil {
+		fmt.Printf("failed to load %s: %s\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+	if r.Seed != 0 {
+		fmt.Printf("seed: %d\n", r.Seed)
+	}
+	fmt.Println("commands: enter or p to pause / play, n to step forward, b to step back, q to quit")
+
+	p := replay.NewPlayer(r)
+	var started time.Time
+	if len(r.Steps) > 0 {
+		started = r.Steps[0].Time
+	}
+	show := func(step *replay.Step) {
+		fmt.Println(textrender.RenderBoard(p.Board()))
+		if step != nil {
+			fmt.Printf(
+				"step %d/%d at +%s: %s\n",
+				p.Position(), len(r.Steps), step.Time.Sub(started).Round(time.Millisecond), step,
+			)
+		} else {
+			fmt.Printf("step %d/%d\n", p.Position(), len(r.Steps))
+		}
+	}
+	forward := func() {
+		if step, ok := p.Forward(); ok {
+			show(&step)
+		} else {
+			fmt.Println("end of replay")
+		}
+	}
+	show(nil)
+
+	lines := make(chan string)
+	go readLines(lines)
+	ticker := time.NewTicker(*delay)
+	defer ticker.Stop()
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				// Without input, play through to the end.
+				lines = nil
+				*paused = false
+				continue
+			}
+			switch line {
+			case "", "p", "pause", "play":
+				*paused = !*paused
+			case "n", "next":
+				*paused = true
+				forward()
+			case "b", "back":
+				*paused = true
+				if p.Back() {
+					show(nil)
+				} else {
+					fmt.Println("start of replay")
+				}
+			case "q", "quit", "exit":
+				return
+			default:
+				fmt.Printf("unknown command: %s\n", line)
+			}
+
+		case <-ticker.C:
+			if *paused {
+				continue
+			}
+			if p.Done() {
+				if lines == nil {
+					return
+				}
+				*paused = true
+				fmt.Println("end of replay")
+				continue
+			}
+			forward()
+		}
+	}
+}
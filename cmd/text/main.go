@@ -2,32 +2,107 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"flag"
 	"fmt"
+	"math/rand"
 	"os"
 	"os/signal"
 	"strconv"
 	"strings"
+	"time"
 
 	board "github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/game"
+	"github.com/levilutz/minesweeper/pkg/replay"
 	"github.com/levilutz/minesweeper/pkg/textrender"
+	"github.com/levilutz/minesweeper/pkg/util"
 )
 
 func main() {
-	boardSize := 8
+	boardWidth := 8
+	boardHeight := 8
 	numMines := 10
 
+	seed := flag.Int64("seed", 0, "seed for the first board's mines (random if 0)")
+	firstClick := flag.String("first-click", "safe", "first reveal protection: none, safe or opening")
+	load := flag.String("load", "", "file to load the first board position from")
+	record := flag.String("record", "", "file to save a replay of the current game to after each move")
+	flag.Parse()
+	policy, err := board.ParseFirstClick(*firstClick)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	// A loaded position sets the dimensions and mine count of later boards.
+	var loaded *board.Board
+	if *load != "" {
+		data, err := os.ReadFile(*load)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		loaded, err = board.Unmarshal(data)
+		if err != nil {
+			fmt.Printf("failed to load %s: %s\n", *load, err)
+			os.Exit(1)
+		}
+		boardWidth, boardHeight = loaded.GetWidth(), loaded.GetHeight()
+		numMines = loaded.NumMines()
+	}
+
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
 
-	b := board.NewBoard(boardSize)
-	if err := b.SpawnMines(numMines); err != nil {
-		panic(err)
+	// Each new board gets its own seed, so any board can be regenerated.
+	b := board.NewBoard(boardWidth, boardHeight)
+	var g *game.Game
+	var rec *replay.Recorder
+	newGame := func(seed int64) {
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		fmt.Printf("seed: %d\n", seed)
+		b.Reset()
+		rng := rand.New(rand.NewSource(seed))
+		if err := b.SpawnMinesOnReveal(numMines, rng, policy); err != nil {
+			panic(err)
+		}
+		g = game.New(b)
+		rec = replay.NewRecorder(g, seed)
+	}
+	if loaded != nil {
+		b = loaded
+		g = game.New(b)
+		rec = replay.NewRecorder(g, 0)
+	} else {
+		newGame(*seed)
+	}
+
+	// Take a move, reporting if it was rejected because the game is over.
+	apply := func(m board.Move) (hitMine, ok bool) {
+		hitMine, err := rec.Apply(m)
+		if err != nil {
+			if errors.Is(err, game.ErrGameOver) {
+				fmt.Printf("%s, undo or reset to keep playing\n", err)
+			} else {
+				fmt.Println(err)
+			}
+			return false, false
+		}
+		return hitMine, true
 	}
 
 	reader := bufio.NewReader(os.Stdin)
 	for {
-		if b.Complete() {
-			fmt.Println("you win!")
+		if *record != "" {
+			if err := replay.Save(*record, rec.Replay()); err != nil {
+				fmt.Printf("failed to save replay: %s\n", err)
+			}
+		}
+		if g.State() == game.Won {
+			fmt.Printf("you win! took %s\n", g.Duration().Round(time.Second))
 		}
 
 		fmt.Println(textrender.RenderBoard(b))
@@ -46,75 +121,90 @@ func main() {
 			return
 
 		} else if cmd[0] == "flag" || cmd[0] == "f" {
-			if len(cmd) < 3 {
-				fmt.Println("must provide x and y coordinates")
-				continue
-			}
-			x, err := strconv.Atoi(cmd[1])
-			if err != nil {
-				fmt.Printf("failed to parse: %s\n", err)
-				continue
-			}
-			y, err := strconv.Atoi(cmd[2])
+			x, y, err := parseTile(b, cmd)
 			if err != nil {
-				fmt.Printf("failed to parse: %s\n", err)
+				fmt.Println(err)
 				continue
 			}
+			tile := util.Vec{X: x, Y: y}
 			if b.HasFlag(x, y) {
-				b.Flag(x, y, false)
-				fmt.Printf("removed flag from (%d, %d)\n", x, y)
-			} else {
-				b.Flag(x, y, true)
+				if _, ok := apply(board.Move{Kind: board.MoveUnflag, Tile: tile}); ok {
+					fmt.Printf("removed flag from (%d, %d)\n", x, y)
+				}
+			} else if _, ok := apply(board.Move{Kind: board.MoveFlag, Tile: tile}); ok {
 				fmt.Printf("added flag to (%d, %d)\n", x, y)
 			}
 
 		} else if cmd[0] == "reveal" || cmd[0] == "r" {
-			if len(cmd) < 3 {
-				fmt.Println("must provide x and y coordinates")
+			x, y, err := parseTile(b, cmd)
+			if err != nil {
+				fmt.Println(err)
 				continue
 			}
-			x, err := strconv.Atoi(cmd[1])
-			if err != nil {
-				fmt.Printf("failed to parse: %s\n", err)
+			if b.HasFlag(x, y) {
+				fmt.Println("cannot reveal tile with flag")
 				continue
 			}
-			y, err := strconv.Atoi(cmd[2])
+			isMine, ok := apply(board.Move{Kind: board.MoveReveal, Tile: util.Vec{X: x, Y: y}})
+			if !ok {
+				continue
+			} else if isMine {
+				fmt.Println("tile has mine, you lose! undo to take it back, or reset")
+			} else {
+				fmt.Printf("revealed (%d, %d)\n", x, y)
+			}
+
+		} else if cmd[0] == "chord" || cmd[0] == "c" {
+			x, y, err := parseTile(b, cmd)
 			if err != nil {
-				fmt.Printf("failed to parse: %s\n", err)
+				fmt.Println(err)
 				continue
 			}
-			if b.HasFlag(x, y) {
-				fmt.Println("cannot reveal tile with flag")
+			if !b.Revealed(x, y) {
+				fmt.Println("cannot chord unrevealed tile")
 				continue
 			}
-			// If fresh game, re-generate until reveal allowed.
-			if !b.HasReveals() {
-				for {
-					isMine := b.Reveal(x, y)
-					if isMine {
-						b.Reset()
-						b.SpawnMines(numMines)
-					} else {
-						break
-					}
-				}
+			hit, ok := apply(board.Move{Kind: board.MoveChord, Tile: util.Vec{X: x, Y: y}})
+			if !ok {
+				continue
+			} else if hit {
+				fmt.Println("chord hit mine, you lose! undo to take it back, or reset")
 			} else {
-				isMine := b.Reveal(x, y)
-				if isMine {
-					fmt.Println("tile has mine, you lose!")
-					b.Reset()
-					b.SpawnMines(numMines)
-				} else {
-					fmt.Printf("revealed (%d, %d)\n", x, y)
-				}
+				fmt.Printf("chorded (%d, %d)\n", x, y)
+			}
+
+		} else if cmd[0] == "undo" || cmd[0] == "u" {
+			if !rec.Undo() {
+				fmt.Println("nothing to undo")
+			}
+
+		} else if cmd[0] == "redo" {
+			if !rec.Redo() {
+				fmt.Println("nothing to redo")
 			}
 
 		} else if cmd[0] == "reset" {
-			b.Reset()
-			b.SpawnMines(numMines)
+			newGame(0)
 
 		} else {
 			fmt.Printf("unknown command: %s\n", cmd[0])
 		}
 	}
 }
+
+// Parse the x and y coordinates of a command, which must be on the board.
+func parseTile(b *board.Board, cmd []string) (x, y int, err error) {
+	if len(cmd) < 3 {
+		return 0, 0, fmt.Errorf("must provide x and y coordinates")
+	}
+	if x, err = strconv.Atoi(cmd[1]); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse: %w", err)
+	}
+	if y, err = strconv.Atoi(cmd[2]); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse: %w", err)
+	}
+	if !b.InBounds(x, y) {
+		return 0, 0, fmt.Errorf("tile (%d, %d) is outside the board", x, y)
+	}
+	return x, y, nil
+}
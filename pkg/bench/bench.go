@@ -0,0 +1,204 @@
+package bench
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/deduce"
+	"github.com/levilutz/minesweeper/pkg/guess"
+	"github.com/levilutz/minesweeper/pkg/solver"
+)
+
+// The maximum deductive steps the deduce strategy may run per move.
+const deduceMaxSteps = 10000
+
+// A way of proposing certain moves, to be benchmarked.
+type Strategy struct {
+	Name string
+
+	// Propose certain moves, returning them and the number of deduction steps run.
+	Analyze func(v board.View) ([]board.Move, int)
+}
+
+// The available strategies, by name.
+var Strategies = map[string]Strategy{
+	"solver": {Name: "solver", Analyze: solver.Analyze},
+	"deduce": {Name: "deduce", Analyze: func(v board.View) ([]board.Move, int) {
+		return deduce.Analyze(v, deduceMaxSteps)
+	}},
+}
+
+// The kind of board to play games on.
+type Config struct {
+	Width  int
+	Height int
+	Mines  int
+}
+
+// Create a config from dimensions and a mine density, rounding to the nearest mine.
+func ConfigFromDensity(width, height int, density float64) Config {
+	return Config{
+		Width:  width,
+		Height: height,
+		Mines:  int(math.Round(float64(width*height) * density)),
+	}
+}
+
+func (c Config) String() string {
+	return fmt.Sprintf("%dx%d/%d", c.Width, c.Height, c.Mines)
+}
+
+// The outcome of a single game.
+type Game struct {
+	Won      bool
+	Guesses  int
+	Moves    int
+	Steps    int
+	Duration time.Duration
+}
+
+// Play a single game with the given strategy, guessing whenever it is stuck.
+func Play(cfg Config, strategy Strategy, seed int64) (Game, error) {
+	start := time.Now()
+	b := board.NewBoard(cfg.Width, cfg.Height)
+	rng := rand.New(rand.NewSource(seed))
+	if err := b.SpawnMinesOnReveal(cfg.Mines, rng, board.FirstClickSafe); err != nil {
+		return Game{}, err
+	}
+	v := b.PlayerView()
+
+	// Each turn changes at least one tile, so this bounds a misbehaving strategy.
+	maxTurns := cfg.Width * cfg.Height * 2
+	game := Game{}
+	for turn := 0; turn < maxTurns && !b.Complete() && !b.HasRevealedMines(); turn++ {
+		moves, steps := strategy.Analyze(v)
+		game.Steps += steps
+		if len(moves) == 0 {
+			g, err := guess.Best(v)
+			if err != nil {
+				break
+			}
+			game.Guesses += 1
+			moves = []board.Move{g.Move()}
+		}
+		// Later moves in the list may already have been made by earlier ones,
+		// such as tiles flood filled by a reveal, so only count moves that acted.
+		for _, move := range moves {
+			before := b.NumActions()
+			hitMine := board.Apply(b, move)
+			if b.NumActions() > before {
+				game.Moves += 1
+			}
+			if hitMine {
+				break
+			}
+		}
+	}
+	game.Won = b.Complete() && !b.HasRevealedMines()
+	game.Duration = time.Since(start)
+	return game, nil
+}
+
+// Aggregate statistics over many games.
+type Summary struct {
+	Strategy       string  `json:"strategy"`
+	Width          int     `json:"width"`
+	Height         int     `json:"height"`
+	Mines          int     `json:"mines"`
+	Games          int     `json:"games"`
+	Wins           int     `json:"wins"`
+	WinRate        float64 `json:"winRate"`
+	WinRateLow     float64 `json:"winRateLow"`
+	WinRateHigh    float64 `json:"winRateHigh"`
+	GuessesPerGame float64 `json:"guessesPerGame"`
+	MovesPerGame   float64 `json:"movesPerGame"`
+	StepsPerGame   float64 `json:"stepsPerGame"`
+
+	// Total time spent playing the games, summed across workers.
+	GameSeconds float64 `json:"gameSeconds"`
+
+	// Elapsed time to play all the games.
+	WallSeconds float64 `json:"wallSeconds"`
+}
+
+// Play one game per seed with the given strategy and summarize the results.
+// Games are spread across the given number of workers. Each game depends only on
+// its seed, so the summary does not depend on the number of workers.
+func Run(cfg Config, strategy Strategy, seeds []int64, workers int) (Summary, error) {
+	start := time.Now()
+	games := make([]Game, len(seeds))
+	errs := make([]error, len(seeds))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				games[i], errs[i] = Play(cfg, strategy, seeds[i])
+			}
+		}()
+	}
+	for i := range seeds {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return Summary{}, err
+		}
+	}
+	summary := Summarize(cfg, strategy, games)
+	summary.WallSeconds = time.Since(start).Seconds()
+	return summary, nil
+}
+
+// Summarize the results of games played with a strategy.
+func Summarize(cfg Config, strategy Strategy, games []Game) Summary {
+	s := Summary{
+		Strategy: strategy.Name,
+		Width:    cfg.Width,
+		Height:   cfg.Height,
+		Mines:    cfg.Mines,
+		Games:    len(games),
+	}
+	if len(games) == 0 {
+		return s
+	}
+	guesses, moves, steps := 0, 0, 0
+	var duration time.Duration
+	for _, game := range games {
+		if game.Won {
+			s.Wins += 1
+		}
+		guesses += game.Guesses
+		moves += game.Moves
+		steps += game.Steps
+		duration += game.Duration
+	}
+	n := float64(len(games))
+	s.WinRate = float64(s.Wins) / n
+	s.WinRateLow, s.WinRateHigh = wilson(s.Wins, len(games), 1.96)
+	s.GuessesPerGame = float64(guesses) / n
+	s.MovesPerGame = float64(moves) / n
+	s.StepsPerGame = float64(steps) / n
+	s.GameSeconds = duration.Seconds()
+	return s
+}
+
+// The Wilson score interval for a binomial proportion, with the given z-score.
+func wilson(successes, trials int, z float64) (lo, hi float64) {
+	n := float64(trials)
+	p := float64(successes) / n
+	denom := 1 + z*z/n
+	center := (p + z*z/(2*n)) / denom
+	margin := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / denom
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
@@ -0,0 +1,58 @@
+package bench_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/levilutz/minesweeper/pkg/bench"
+)
+
+func TestRunDeterministic(t *testing.T) {
+	cfg := bench.Config{Width: 16, Height: 16, Mines: 40}
+	seeds := []int64{}
+	for seed := int64(1); seed <= 20; seed++ {
+		seeds = append(seeds, seed)
+	}
+	for _, strategy := range bench.Strategies {
+		for _, seed := range seeds[:5] {
+			a, err := bench.Play(cfg, strategy, seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := bench.Play(cfg, strategy, seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			a.Duration, b.Duration = 0, 0
+			if a != b {
+				t.Fatalf("%s seed %d: games differ: %+v and %+v", strategy.Name, seed, a, b)
+			}
+		}
+
+		serial, err := bench.Run(cfg, strategy, seeds, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parallel, err := bench.Run(cfg, strategy, seeds, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serial.GameSeconds, parallel.GameSeconds = 0, 0
+		serial.WallSeconds, parallel.WallSeconds = 0, 0
+		if serial != parallel {
+			t.Fatalf("%s: summaries differ: %+v and %+v", strategy.Name, serial, parallel)
+		}
+	}
+}
+
+func TestSummarizeDuration(t *testing.T) {
+	cfg := bench.Config{Width: 9, Height: 9, Mines: 10}
+	games := []bench.Game{
+		{Won: true, Duration: time.Second},
+		{Duration: 2 * time.Second},
+	}
+	s := bench.Summarize(cfg, bench.Strategies["deduce"], games)
+	if s.GameSeconds != 3 || s.Wins != 1 {
+		t.Fatalf("unexpected summary: %+v", s)
+	}
+}
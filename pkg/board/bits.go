@@ -0,0 +1,23 @@
+package board
+
+// A fixed-size set of bits, indexed from 0.
+type bitset []uint64
+
+// Create a bitset with room for n bits, all unset.
+func newBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+// Check whether the given bit is set.
+func (s bitset) get(i int) bool {
+	return s[i>>6]&(1<<(uint(i)&63)) != 0
+}
+
+// Set or unset the given bit.
+func (s bitset) put(i int, v bool) {
+	if v {
+		s[i>>6] |= 1 << (uint(i) & 63)
+	} else {
+		s[i>>6] &^= 1 << (uint(i) & 63)
+	}
+}
@@ -2,139 +2,183 @@ package board
 
 import (
 	"fmt"
+	"math/rand"
 
 	"github.com/levilutz/minesweeper/pkg/util"
 )
 
 // The game board
 type Board struct {
-	size          int
-	mines         [][]bool
-	flags         [][]bool
-	revealed      [][]bool
-	neighbors     [][]int
-	neighborCache [][][]util.Vec
+	width  int
+	height int
+
+	// Tile state, indexed by y*width+x.
+	mines     bitset
+	flags     bitset
+	revealed  bitset
+	neighbors []uint8
+
+	numMines        int
+	numFlags        int
+	numFlaggedMines int
+
+	// Revealed tiles, and how many of them are mines.
+	numRevealed      int
+	numRevealedMines int
+
+	// Mines to place on the first reveal, protecting it by pendingPolicy.
+	pendingMines  int
+	pendingRng    *rand.Rand
+	pendingPolicy FirstClick
+
+	// Recorded actions, and the action currently being recorded.
+	undoStack   []action
+	redoStack   []action
+	current     action
+	actionDepth int
 }
 
-// Create a new game board.
-func NewBoard(size int) *Board {
+// Create a new game board with the given dimensions.
+func NewBoard(width, height int) *Board {
 	return &Board{
-		size:          size,
-		mines:         util.DArray[bool](size),
-		flags:         util.DArray[bool](size),
-		revealed:      util.DArray[bool](size),
-		neighbors:     util.DArray[int](size),
-		neighborCache: util.DArray[[]util.Vec](size),
+		width:     width,
+		height:    height,
+		mines:     newBitset(width * height),
+		flags:     newBitset(width * height),
+		revealed:  newBitset(width * height),
+		neighbors: make([]uint8, width*height),
 	}
 }
 
 // Reset the game board.
 func (b *Board) Reset() {
-	for x := 0; x < b.size; x++ {
-		for y := 0; y < b.size; y++ {
-			b.mines[x][y] = false
-			b.flags[x][y] = false
-			b.revealed[x][y] = false
-			b.neighbors[x][y] = 0
-		}
-	}
+	clear(b.mines)
+	clear(b.flags)
+	clear(b.revealed)
+	clear(b.neighbors)
+	b.numMines = 0
+	b.numFlags = 0
+	b.numFlaggedMines = 0
+	b.numRevealed = 0
+	b.numRevealedMines = 0
+	b.pendingMines = 0
+	b.pendingRng = nil
+	b.clearHistory()
+}
+
+// Copy the board, without its history or any mines not yet placed.
+func (b *Board) Clone() *Board {
+	out := NewBoard(b.width, b.height)
+	copy(out.mines, b.mines)
+	copy(out.flags, b.flags)
+	copy(out.revealed, b.revealed)
+	copy(out.neighbors, b.neighbors)
+	out.numMines = b.numMines
+	out.numFlags = b.numFlags
+	out.numFlaggedMines = b.numFlaggedMines
+	out.numRevealed = b.numRevealed
+	out.numRevealedMines = b.numRevealedMines
+	return out
+}
+
+// Get the width of the board.
+func (b *Board) GetWidth() int {
+	return b.width
+}
+
+// Get the height of the board.
+func (b *Board) GetHeight() int {
+	return b.height
 }
 
-// Get the size of the board.
-func (b *Board) GetSize() int {
-	return b.size
+// Get the number of mines on the board, including any not yet placed.
+func (b *Board) NumMines() int {
+	return b.numMines + b.pendingMines
 }
 
 // Check whether the game has any revealed tiles.
 func (b *Board) HasReveals() bool {
-	for x := 0; x < b.size; x++ {
-		for y := 0; y < b.size; y++ {
-			if b.revealed[x][y] {
-				return true
-			}
-		}
-	}
-	return false
+	return b.numRevealed > 0
 }
 
 // Check whether the game is complete (all non-mines revealed).
 func (b *Board) Complete() bool {
-	for x := 0; x < b.size; x++ {
-		for y := 0; y < b.size; y++ {
-			if !b.mines[x][y] && !b.revealed[x][y] {
-				return false
-			}
-		}
-	}
-	return true
+	return b.NumRevealedSafe() == b.width*b.height-b.NumMines()
 }
 
 // Check whether any mines have been revealed (game loss).
 func (b *Board) HasRevealedMines() bool {
-	for x := 0; x < b.size; x++ {
-		for y := 0; y < b.size; y++ {
-			if b.mines[x][y] && b.revealed[x][y] {
-				return true
-			}
-		}
-	}
-	return false
+	return b.numRevealedMines > 0
+}
+
+// Get the number of revealed tiles that are not mines.
+func (b *Board) NumRevealedSafe() int {
+	return b.numRevealed - b.numRevealedMines
 }
 
 // Count the number of remaining unflagged mines.
 func (b *Board) UnflaggedMines() int {
-	out := 0
-	for x := 0; x < b.size; x++ {
-		for y := 0; y < b.size; y++ {
-			if b.mines[x][y] && !b.flags[x][y] {
-				out += 1
-			}
-		}
-	}
-	return out
+	return b.numMines - b.numFlaggedMines
 }
 
 // Get data for the given tile.
 func (b *Board) GetTile(x, y int) (hasMine, hasFlag, revealed bool, neighbors int) {
-	return b.mines[x][y], b.flags[x][y], b.revealed[x][y], b.neighbors[x][y]
+	i := b.index(x, y)
+	return b.mines.get(i), b.flags.get(i), b.revealed.get(i), int(b.neighbors[i])
 }
 
 // Check whether the given tile has a flag.
 func (b *Board) HasFlag(x, y int) bool {
-	return b.flags[x][y]
+	return b.flags.get(b.index(x, y))
 }
 
 // Check whether the given tile has a mine.
 func (b *Board) HasMine(x, y int) bool {
-	return b.mines[x][y]
+	return b.mines.get(b.index(x, y))
 }
 
 // Get the number of neighbors the given tile has.
 func (b *Board) GetNumNeighbors(x, y int) int {
-	return b.neighbors[x][y]
+	return int(b.neighbors[b.index(x, y)])
 }
 
 // Check whether the given tile is revealed.
 func (b *Board) Revealed(x, y int) bool {
-	return b.revealed[x][y]
+	return b.revealed.get(b.index(x, y))
 }
 
 // Place a mine.
 func (b *Board) PlaceMine(x, y int) {
-	b.mines[x][y] = true
-	for _, neighbor := range util.GetNeighbors(x, y, b.size) {
-		b.neighbors[neighbor.X][neighbor.Y] += 1
+	i := b.index(x, y)
+	if b.mines.get(i) {
+		return
+	}
+	b.mines.put(i, true)
+	b.numMines += 1
+	if b.revealed.get(i) {
+		b.numRevealedMines += 1
+	}
+	if b.flags.get(i) {
+		b.numFlaggedMines += 1
+	}
+	var buf [8]int
+	for _, j := range b.neighborsOf(i, buf[:0]) {
+		b.neighbors[j] += 1
 	}
 }
 
 // Reveal a single tile. Returns whether the revealed tile was a mine.
 func (b *Board) Reveal(x, y int) (isMine bool) {
-	b.revealed[x][y] = true
-	if b.mines[x][y] {
+	b.beginAction()
+	defer b.endAction()
+	b.placePendingMines(x, y)
+	i := b.index(x, y)
+	b.reveal(i)
+	if b.mines.get(i) {
 		return true
 	} else {
-		if b.neighbors[x][y] == 0 {
-			b.clearZerosFrom(x, y)
+		if b.neighbors[i] == 0 {
+			b.clearZerosFrom(i)
 		}
 		return false
 	}
@@ -142,17 +186,59 @@ func (b *Board) Reveal(x, y int) (isMine bool) {
 
 // Set / remove flag for a single tile.
 func (b *Board) Flag(x, y int, flag bool) {
-	if !b.revealed[x][y] {
-		b.flags[x][y] = flag
+	i := b.index(x, y)
+	if b.revealed.get(i) || b.flags.get(i) == flag {
+		return
+	}
+	b.beginAction()
+	defer b.endAction()
+	b.flag(i, flag)
+	if flag {
+		b.record(changeFlag, i)
+	} else {
+		b.record(changeUnflag, i)
 	}
 }
 
-// Spawn the given number of mines on the board. Returns err if impossible.
-func (b *Board) SpawnMines(num int) error {
-	open := make([]util.Vec, 0)
-	for x := 0; x < b.size; x++ {
-		for y := 0; y < b.size; y++ {
-			if !b.mines[x][y] {
+// Reveal all unflagged neighbors of a revealed tile, if it has as many flagged
+// neighbors as it has neighboring mines. Returns the positions of any mines hit.
+func (b *Board) Chord(x, y int) []util.Vec {
+	i := b.index(x, y)
+	if !b.revealed.get(i) || b.mines.get(i) {
+		return nil
+	}
+	var buf [8]int
+	neighbors := b.neighborsOf(i, buf[:0])
+	numFlagged := 0
+	for _, j := range neighbors {
+		if b.flags.get(j) {
+			numFlagged += 1
+		}
+	}
+	if numFlagged != int(b.neighbors[i]) {
+		return nil
+	}
+	b.beginAction()
+	defer b.endAction()
+	hit := []util.Vec{}
+	for _, j := range neighbors {
+		if !b.revealed.get(j) && !b.flags.get(j) {
+			neighbor := b.vec(j)
+			if b.Reveal(neighbor.X, neighbor.Y) {
+				hit = append(hit, neighbor)
+			}
+		}
+	}
+	return hit
+}
+
+// Spawn the given number of mines on the board, placed using the given source.
+// Returns err if impossible.
+func (b *Board) SpawnMines(num int, rng *rand.Rand) error {
+	open := make([]util.Vec, 0, b.width*b.height-b.numMines)
+	for x := 0; x < b.width; x++ {
+		for y := 0; y < b.height; y++ {
+			if !b.mines.get(b.index(x, y)) {
 				open = append(open, util.Vec{X: x, Y: y})
 			}
 		}
@@ -160,25 +246,108 @@ func (b *Board) SpawnMines(num int) error {
 	if len(open) < num {
 		return fmt.Errorf("insufficient empty squares to place this many mines")
 	}
-	open = util.Shuffle(open)
+	open = util.Shuffle(open, rng)
 	for i := 0; i < num; i++ {
 		b.PlaceMine(open[i].X, open[i].Y)
 	}
 	return nil
 }
 
+// Whether a tile is on the board.
+func (b *Board) InBounds(x, y int) bool {
+	return x >= 0 && x < b.width && y >= 0 && y < b.height
+}
+
+// Get the storage index of a tile. Panics if the tile is outside the board,
+// rather than returning the index of a different tile.
+func (b *Board) index(x, y int) int {
+	if !b.InBounds(x, y) {
+		panic(fmt.Sprintf("tile (%d, %d) is outside the %dx%d board", x, y, b.width, b.height))
+	}
+	return y*b.width + x
+}
+
+// Get the tile at a storage index.
+func (b *Board) vec(i int) util.Vec {
+	return util.Vec{X: i % b.width, Y: i / b.width}
+}
+
+// Append the storage indices of a tile's neighbors to out, which can be backed
+// by an array on the stack to avoid allocating.
+func (b *Board) neighborsOf(i int, out []int) []int {
+	x, y := i%b.width, i/b.width
+	for dy := -1; dy <= 1; dy++ {
+		if y+dy < 0 || y+dy >= b.height {
+			continue
+		}
+		for dx := -1; dx <= 1; dx++ {
+			if (dx == 0 && dy == 0) || x+dx < 0 || x+dx >= b.width {
+				continue
+			}
+			out = append(out, i+dy*b.width+dx)
+		}
+	}
+	return out
+}
+
+// Mark a single tile as revealed, removing any flag from it.
+func (b *Board) reveal(i int) {
+	if b.revealed.get(i) {
+		return
+	}
+	if b.flags.get(i) {
+		b.flag(i, false)
+		b.record(changeUnflag, i)
+	}
+	b.setRevealed(i, true)
+	b.record(changeReveal, i)
+}
+
+// Set whether a single tile is revealed, keeping the revealed counts.
+func (b *Board) setRevealed(i int, revealed bool) {
+	if b.revealed.get(i) == revealed {
+		return
+	}
+	b.revealed.put(i, revealed)
+	delta := 1
+	if !revealed {
+		delta = -1
+	}
+	b.numRevealed += delta
+	if b.mines.get(i) {
+		b.numRevealedMines += delta
+	}
+}
+
+// Set / remove the flag on a single tile, keeping the flag counts.
+func (b *Board) flag(i int, flag bool) {
+	if b.flags.get(i) == flag {
+		return
+	}
+	b.flags.put(i, flag)
+	delta := 1
+	if !flag {
+		delta = -1
+	}
+	b.numFlags += delta
+	if b.mines.get(i) {
+		b.numFlaggedMines += delta
+	}
+}
+
 // From the given starting point, reveal everything cleared by zeros.
-func (b *Board) clearZerosFrom(x, y int) {
-	q := make([]util.Vec, 0)
-	q = append(q, util.Vec{X: x, Y: y})
-	for len(q) > 0 {
-		v := q[0]
-		q = q[1:]
-		b.revealed[v.X][v.Y] = true
-		if !b.mines[v.X][v.Y] && b.neighbors[v.X][v.Y] == 0 {
-			for _, neighbor := range util.GetNeighbors(v.X, v.Y, b.size) {
-				if !b.revealed[neighbor.X][neighbor.Y] {
-					q = append(q, neighbor)
+func (b *Board) clearZerosFrom(i int) {
+	// Tiles are revealed as they're queued, so that each is only queued once.
+	q := []int{i}
+	b.reveal(i)
+	var buf [8]int
+	for head := 0; head < len(q); head++ {
+		next := q[head]
+		if !b.mines.get(next) && b.neighbors[next] == 0 {
+			for _, j := range b.neighborsOf(next, buf[:0]) {
+				if !b.revealed.get(j) {
+					b.reveal(j)
+					q = append(q, j)
 				}
 			}
 		}
@@ -0,0 +1,217 @@
+package board_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+// A 1000x1000 board with sparse mines, and a zero on the bottom row that floods
+// most of the board when revealed.
+func largeBoard(b *testing.B) (*board.Board, util.Vec) {
+	out := board.NewBoard(1000, 1000)
+	if err := out.SpawnMines(2000, rand.New(rand.NewSource(1))); err != nil {
+		b.Fatal(err)
+	}
+	for x := 0; x < 1000; x++ {
+		if !out.HasMine(x, 0) && out.GetNumNeighbors(x, 0) == 0 {
+			return out, util.Vec{X: x, Y: 0}
+		}
+	}
+	b.Fatal("no zero to flood from")
+	return nil, util.Vec{}
+}
+
+func TestCounters(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for round := 0; round < 50; round++ {
+		b := board.NewBoard(5+rng.Intn(20), 5+rng.Intn(20))
+		if err := b.SpawnMines(rng.Intn(20), rng); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 30; i++ {
+			x, y := rng.Intn(b.GetWidth()), rng.Intn(b.GetHeight())
+			switch rng.Intn(4) {
+			case 0:
+				b.Flag(x, y, !b.HasFlag(x, y))
+			case 1:
+				b.Reveal(x, y)
+			case 2:
+				b.Chord(x, y)
+			case 3:
+				b.Undo()
+			}
+
+			hasReveals, hasRevealedMines, complete, unflagged := false, false, true, 0
+			for x := 0; x < b.GetWidth(); x++ {
+				for y := 0; y < b.GetHeight(); y++ {
+					mine, flag, revealed, _ := b.GetTile(x, y)
+					hasReveals = hasReveals || revealed
+					hasRevealedMines = hasRevealedMines || (mine && revealed)
+					complete = complete && (mine || revealed)
+					if mine && !flag {
+						unflagged += 1
+					}
+				}
+			}
+			if b.HasReveals() != hasReveals || b.HasRevealedMines() != hasRevealedMines ||
+				b.Complete() != complete || b.UnflaggedMines() != unflagged {
+				t.Fatalf("round %d: counters disagree with tiles after %d actions", round, i+1)
+			}
+		}
+	}
+}
+
+// The mines on the board, indexed [x][y].
+func mineLayout(b *board.Board) [][]bool {
+	out := util.DArray[bool](b.GetWidth(), b.GetHeight())
+	for x := 0; x < b.GetWidth(); x++ {
+		for y := 0; y < b.GetHeight(); y++ {
+			out[x][y] = b.HasMine(x, y)
+		}
+	}
+	return out
+}
+
+func countMines(layout [][]bool) int {
+	out := 0
+	for _, col := range layout {
+		for _, mine := range col {
+			if mine {
+				out += 1
+			}
+		}
+	}
+	return out
+}
+
+func TestFirstClick(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, policy := range []board.FirstClick{
+		board.FirstClickUnprotected, board.FirstClickSafe, board.FirstClickOpening,
+	} {
+		for round := 0; round < 100; round++ {
+			width, height := 5+rng.Intn(10), 5+rng.Intn(10)
+			// Include nearly full boards, leaving room only for the first reveal.
+			num := rng.Intn(width*height - 1)
+			if round%4 == 0 {
+				num = width*height - 1
+			}
+			b := board.NewBoard(width, height)
+			if err := b.SpawnMinesOnReveal(num, rng, policy); err != nil {
+				t.Fatal(err)
+			}
+			before := mineLayout(b)
+			x, y := rng.Intn(width), rng.Intn(height)
+			hitMine := b.Reveal(x, y)
+			after := mineLayout(b)
+
+			if n := countMines(after); n != num {
+				t.Fatalf("%s round %d: expected %d mines, got %d", policy, round, num, n)
+			}
+			switch policy {
+			case board.FirstClickUnprotected:
+				for xi := range before {
+					for yi := range before[xi] {
+						if before[xi][yi] != after[xi][yi] {
+							t.Fatalf("%s round %d: layout changed at (%d, %d)", policy, round, xi, yi)
+						}
+					}
+				}
+			case board.FirstClickSafe:
+				if hitMine {
+					t.Fatalf("%s round %d: first reveal hit a mine", policy, round)
+				}
+			case board.FirstClickOpening:
+				if hitMine {
+					t.Fatalf("%s round %d: first reveal hit a mine", policy, round)
+				}
+				neighbors := util.GetNeighbors(x, y, width, height)
+				fits := width*height-len(neighbors)-1 >= num
+				if fits && b.GetNumNeighbors(x, y) != 0 {
+					t.Fatalf("%s round %d: first reveal shows %d", policy, round, b.GetNumNeighbors(x, y))
+				}
+			}
+		}
+	}
+}
+
+func TestPlayerActor(t *testing.T) {
+	b := board.NewBoard(5, 5)
+	a := b.PlayerActor()
+	if _, ok := a.(*board.Board); ok {
+		t.Fatal("expected player actor to hide the board")
+	}
+	a.Flag(0, 0, true)
+	if !b.HasFlag(0, 0) {
+		t.Fatal("expected flag through player actor")
+	}
+	if a.Reveal(4, 4) || !b.Revealed(4, 4) {
+		t.Fatal("expected reveal through player actor")
+	}
+}
+
+func BenchmarkSpawnMines(b *testing.B) {
+	out := board.NewBoard(1000, 1000)
+	rng := rand.New(rand.NewSource(1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out.Reset()
+		if err := out.SpawnMines(200000, rng); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFloodFill(b *testing.B) {
+	out, start := largeBoard(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out.Reveal(start.X, start.Y)
+		b.StopTimer()
+		out.Undo()
+		b.StartTimer()
+	}
+}
+
+func BenchmarkUndoFloodFill(b *testing.B) {
+	out, start := largeBoard(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		out.Reveal(start.X, start.Y)
+		b.StartTimer()
+		out.Undo()
+	}
+}
+
+func BenchmarkScanPlayerView(b *testing.B) {
+	out, start := largeBoard(b)
+	out.Reveal(start.X, start.Y)
+	v := out.PlayerView()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		numbers := 0
+		for y := 0; y < v.GetHeight(); y++ {
+			for x := 0; x < v.GetWidth(); x++ {
+				if v.Revealed(x, y) && !v.HasFlag(x, y) && v.GetNumNeighbors(x, y) > 0 {
+					numbers += 1
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkCounters(b *testing.B) {
+	out, start := largeBoard(b)
+	out.Reveal(start.X, start.Y)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = out.HasReveals()
+		_ = out.Complete()
+		_ = out.HasRevealedMines()
+		_ = out.UnflaggedMines()
+	}
+}
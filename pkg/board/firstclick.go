@@ -0,0 +1,93 @@
+package board
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/levilutz/minesweeper/pkg/set"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+// How the first reveal of a game is protected from mines.
+type FirstClick int
+
+const (
+	// The first reveal may hit a mine.
+	FirstClickUnprotected FirstClick = iota
+
+	// The first reveal never hits a mine.
+	FirstClickSafe
+
+	// The first reveal is always a zero, opening an area around it.
+	// Falls back to FirstClickSafe if the board is too dense to fit the opening.
+	FirstClickOpening
+)
+
+func (p FirstClick) String() string {
+	switch p {
+	case FirstClickUnprotected:
+		return "none"
+	case FirstClickSafe:
+		return "safe"
+	case FirstClickOpening:
+		return "opening"
+	}
+	return fmt.Sprintf("FirstClick(%d)", int(p))
+}
+
+// Parse a first-click policy from its name.
+func ParseFirstClick(s string) (FirstClick, error) {
+	for _, p := range []FirstClick{FirstClickUnprotected, FirstClickSafe, FirstClickOpening} {
+		if p.String() == s {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown first-click policy: %s", s)
+}
+
+// Spawn the given number of mines on the board, protecting the first reveal
+// according to the given policy. Placement is deferred until the first reveal.
+// Returns err if impossible.
+func (b *Board) SpawnMinesOnReveal(num int, rng *rand.Rand, policy FirstClick) error {
+	if policy == FirstClickUnprotected {
+		return b.SpawnMines(num, rng)
+	}
+	if b.width*b.height-b.numMines-1 < num {
+		return fmt.Errorf("insufficient empty squares to place this many mines")
+	}
+	b.pendingMines = num
+	b.pendingRng = rng
+	b.pendingPolicy = policy
+	return nil
+}
+
+// Place any mines deferred by SpawnMinesOnReveal, given the first reveal.
+func (b *Board) placePendingMines(x, y int) {
+	if b.pendingMines == 0 {
+		return
+	}
+	protected := set.NewSet(util.Vec{X: x, Y: y})
+	if b.pendingPolicy == FirstClickOpening {
+		neighbors := util.GetNeighbors(x, y, b.width, b.height)
+		if b.width*b.height-b.numMines-len(neighbors)-1 >= b.pendingMines {
+			for _, neighbor := range neighbors {
+				protected[neighbor] = struct{}{}
+			}
+		}
+	}
+
+	open := make([]util.Vec, 0)
+	for xi := 0; xi < b.width; xi++ {
+		for yi := 0; yi < b.height; yi++ {
+			if !b.mines.get(b.index(xi, yi)) && !protected.Has(util.Vec{X: xi, Y: yi}) {
+				open = append(open, util.Vec{X: xi, Y: yi})
+			}
+		}
+	}
+	open = util.Shuffle(open, b.pendingRng)
+	for i := 0; i < b.pendingMines; i++ {
+		b.PlaceMine(open[i].X, open[i].Y)
+	}
+	b.pendingMines = 0
+	b.pendingRng = nil
+}
@@ -0,0 +1,109 @@
+package board
+
+// The kind of change an action made to a single tile.
+type changeKind int
+
+const (
+	changeReveal changeKind = iota
+	changeFlag
+	changeUnflag
+)
+
+// A change an action made to a single tile, by storage index.
+type change struct {
+	kind changeKind
+	tile int
+}
+
+// The tile changes made by one call to Reveal, Flag or Chord, in order.
+type action []change
+
+// Start recording an action. Nested calls join the outermost action.
+func (b *Board) beginAction() {
+	b.actionDepth += 1
+}
+
+// Finish recording an action, adding it to the history if it changed anything.
+// A new action discards anything that could have been redone.
+func (b *Board) endAction() {
+	b.actionDepth -= 1
+	if b.actionDepth > 0 || len(b.current) == 0 {
+		return
+	}
+	b.undoStack = append(b.undoStack, b.current)
+	b.redoStack = nil
+	b.current = nil
+}
+
+// Record a tile change as part of the current action, if one is being recorded.
+func (b *Board) record(kind changeKind, i int) {
+	if b.actionDepth > 0 {
+		b.current = append(b.current, change{kind: kind, tile: i})
+	}
+}
+
+// Forget all recorded actions.
+func (b *Board) clearHistory() {
+	b.undoStack = nil
+	b.redoStack = nil
+	b.current = nil
+}
+
+// Whether there is an action to undo.
+func (b *Board) CanUndo() bool {
+	return len(b.undoStack) > 0
+}
+
+// Get the number of actions that can be undone.
+func (b *Board) NumActions() int {
+	return len(b.undoStack)
+}
+
+// Whether there is an undone action to redo.
+func (b *Board) CanRedo() bool {
+	return len(b.redoStack) > 0
+}
+
+// Undo the last action, including any tiles it flood filled. Returns whether
+// there was an action to undo. Mines placed by the first reveal stay placed.
+func (b *Board) Undo() bool {
+	if len(b.undoStack) == 0 {
+		return false
+	}
+	last := b.undoStack[len(b.undoStack)-1]
+	b.undoStack = b.undoStack[:len(b.undoStack)-1]
+	for i := len(last) - 1; i >= 0; i-- {
+		c := last[i]
+		switch c.kind {
+		case changeReveal:
+			b.setRevealed(c.tile, false)
+		case changeFlag:
+			b.flag(c.tile, false)
+		case changeUnflag:
+			b.flag(c.tile, true)
+		}
+	}
+	b.redoStack = append(b.redoStack, last)
+	return true
+}
+
+// Redo the last undone action. Returns whether there was an action to redo.
+func (b *Board) Redo() bool {
+	if len(b.redoStack) == 0 {
+		return false
+	}
+	next := b.redoStack[len(b.redoStack)-1]
+	b.redoStack = b.redoStack[:len(b.redoStack)-1]
+	for _, c := range next {
+		switch c.kind {
+		case changeReveal:
+			b.setRevealed(c.tile, true)
+		case changeFlag:
+			b.flag(c.tile, true)
+		case changeUnflag:
+			b.flag(c.tile, false)
+		}
+	}
+	b.undoStack = append(b.undoStack, next)
+	return true
+}
@@ -0,0 +1,115 @@
+package board_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+)
+
+// Copy a board through the text format.
+func snapshot(t *testing.T, b *board.Board) *board.Board {
+	t.Helper()
+	data, err := board.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := board.Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestUndoRedo(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for round := 0; round < 50; round++ {
+		b := board.NewBoard(5+rng.Intn(20), 5+rng.Intn(20))
+		if err := b.SpawnMines(rng.Intn(20), rng); err != nil {
+			t.Fatal(err)
+		}
+		states := []*board.Board{snapshot(t, b)}
+		for i := 0; i < 20; i++ {
+			// Only act on hidden tiles, so every action changes the board.
+			x, y := rng.Intn(b.GetWidth()), rng.Intn(b.GetHeight())
+			if b.Revealed(x, y) {
+				continue
+			}
+			if rng.Intn(2) == 0 {
+				b.Flag(x, y, !b.HasFlag(x, y))
+			} else {
+				b.Reveal(x, y)
+			}
+			states = append(states, snapshot(t, b))
+		}
+		for i := len(states) - 2; i >= 0; i-- {
+			if !b.Undo() {
+				t.Fatalf("round %d: expected to undo to state %d", round, i)
+			}
+			assertSameBoard(t, states[i], b)
+		}
+		if b.Undo() {
+			t.Fatalf("round %d: undid past the start", round)
+		}
+		for i := 1; i < len(states); i++ {
+			if !b.Redo() {
+				t.Fatalf("round %d: expected to redo to state %d", round, i)
+			}
+			assertSameBoard(t, states[i], b)
+		}
+		if b.Redo() {
+			t.Fatalf("round %d: redid past the end", round)
+		}
+	}
+}
+
+func TestChordIsOneAction(t *testing.T) {
+	b, err := board.Unmarshal([]byte("4 3\n....\n.1..\n*...\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Flag(0, 0, true)
+	b.Chord(1, 1)
+	if !b.Complete() {
+		t.Fatal("expected chord to clear the board")
+	}
+	b.Undo()
+	if !b.Revealed(1, 1) || b.Revealed(0, 1) || !b.HasFlag(0, 0) {
+		t.Fatal("expected undo to take back the whole chord")
+	}
+	if b.Redo(); !b.Complete() {
+		t.Fatal("expected redo to clear the board again")
+	}
+}
+
+func TestActionClearsRedo(t *testing.T) {
+	b := board.NewBoard(3, 3)
+	b.Flag(0, 0, true)
+	b.Undo()
+	b.Flag(1, 1, true)
+	if b.Redo() {
+		t.Fatal("expected a new action to discard the redo history")
+	}
+	if b.HasFlag(0, 0) || !b.HasFlag(1, 1) {
+		t.Fatal("unexpected flags after redo")
+	}
+}
+
+func TestFloodFillClearsFlags(t *testing.T) {
+	b, err := board.Unmarshal([]byte("4 3\n...*\n....\n....\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Flag(0, 2, true)
+	b.Reveal(0, 0)
+	if !b.Revealed(0, 2) || b.HasFlag(0, 2) {
+		t.Fatal("expected flood fill to reveal and unflag (0, 2)")
+	}
+	if n := b.PlayerView().RemainingMines(); n != 1 {
+		t.Fatalf("expected 1 remaining mine, got %d", n)
+	}
+	b.Undo()
+	if b.Revealed(0, 2) || !b.HasFlag(0, 2) {
+		t.Fatal("expected undo to restore the flag")
+	}
+}
@@ -0,0 +1,159 @@
+package board
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// A tile in the JSON encoding of a board.
+type jsonTile struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+
+	// The number shown on a revealed tile.
+	Number *int `json:"number,omitempty"`
+
+	// Whether a revealed tile is a mine.
+	Mine bool `json:"mine,omitempty"`
+}
+
+// The JSON encoding of a board. Mines are always present, even if there are
+// none, so that a player-visible encoding is never mistaken for a board without
+// mines.
+type jsonBoard struct {
+	Width          int        `json:"width"`
+	Height         int        `json:"height"`
+	Mines          []jsonTile `json:"mines"`
+	RemainingMines *int       `json:"remainingMines,omitempty"`
+	Flags          []jsonTile `json:"flags"`
+	Revealed       []jsonTile `json:"revealed"`
+}
+
+// The player-visible JSON encoding of a board, which gives the number of mines
+// remaining instead of their locations.
+type jsonPlayerBoard struct {
+	Width          int        `json:"width"`
+	Height         int        `json:"height"`
+	RemainingMines int        `json:"remainingMines"`
+	Flags          []jsonTile `json:"flags"`
+	Revealed       []jsonTile `json:"revealed"`
+}
+
+// Encode the player-visible state of a board as JSON, without mine locations.
+func MarshalPlayerJSON(v View) ([]byte, error) {
+	out := jsonPlayerBoard{
+		Width:          v.GetWidth(),
+		Height:         v.GetHeight(),
+		RemainingMines: v.RemainingMines(),
+		Flags:          []jsonTile{},
+		Revealed:       []jsonTile{},
+	}
+	for y := 0; y < v.GetHeight(); y++ {
+		for x := 0; x < v.GetWidth(); x++ {
+			if v.HasFlag(x, y) {
+				out.Flags = append(out.Flags, jsonTile{X: x, Y: y})
+			}
+			if v.Revealed(x, y) {
+				tile := jsonTile{X: x, Y: y}
+				if n := v.GetNumNeighbors(x, y); n >= 0 {
+					tile.Number = &n
+				} else {
+					tile.Mine = true
+				}
+				out.Revealed = append(out.Revealed, tile)
+			}
+		}
+	}
+	return json.Marshal(out)
+}
+
+// Encode the full state of the board as JSON, including mine locations.
+// Returns err if the board's mines have not been placed yet.
+func (b *Board) MarshalJSON() ([]byte, error) {
+	if b.pendingMines > 0 {
+		return nil, fmt.Errorf("cannot marshal board before its mines are placed")
+	}
+	out := jsonBoard{
+		Width:    b.width,
+		Height:   b.height,
+		Mines:    []jsonTile{},
+		Flags:    []jsonTile{},
+		Revealed: []jsonTile{},
+	}
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			i := b.index(x, y)
+			if b.mines.get(i) {
+				out.Mines = append(out.Mines, jsonTile{X: x, Y: y})
+			}
+			if b.flags.get(i) {
+				out.Flags = append(out.Flags, jsonTile{X: x, Y: y})
+			}
+			if b.revealed.get(i) {
+				tile := jsonTile{X: x, Y: y}
+				if b.mines.get(i) {
+					tile.Mine = true
+				} else {
+					n := int(b.neighbors[i])
+					tile.Number = &n
+				}
+				out.Revealed = append(out.Revealed, tile)
+			}
+		}
+	}
+	return json.Marshal(out)
+}
+
+// Decode the full state of a board from JSON, replacing the board's contents.
+// Returns err for player-visible encodings, which have no mine locations.
+func (b *Board) UnmarshalJSON(data []byte) error {
+	var in jsonBoard
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if in.Width <= 0 || in.Height <= 0 {
+		return fmt.Errorf("invalid dimensions: %dx%d", in.Width, in.Height)
+	}
+	if in.Mines == nil && in.RemainingMines != nil {
+		return fmt.Errorf("cannot decode a player-visible board without mines")
+	} else if in.Mines == nil {
+		return fmt.Errorf("missing mines")
+	}
+
+	inBounds := func(tile jsonTile) error {
+		if tile.X < 0 || tile.X >= in.Width || tile.Y < 0 || tile.Y >= in.Height {
+			return fmt.Errorf("tile (%d, %d) is outside the board", tile.X, tile.Y)
+		}
+		return nil
+	}
+	out := NewBoard(in.Width, in.Height)
+	for _, tile := range in.Mines {
+		if err := inBounds(tile); err != nil {
+			return err
+		}
+		out.PlaceMine(tile.X, tile.Y)
+	}
+	for _, tile := range in.Flags {
+		if err := inBounds(tile); err != nil {
+			return err
+		}
+		out.flag(out.index(tile.X, tile.Y), true)
+	}
+	for _, tile := range in.Revealed {
+		if err := inBounds(tile); err != nil {
+			return err
+		}
+		if tile.Mine && !out.HasMine(tile.X, tile.Y) {
+			return fmt.Errorf("tile (%d, %d): revealed as a mine but has none", tile.X, tile.Y)
+		}
+		if tile.Number != nil && *tile.Number != out.GetNumNeighbors(tile.X, tile.Y) {
+			return fmt.Errorf(
+				"tile (%d, %d): shows %d but has %d neighboring mines",
+				tile.X, tile.Y, *tile.Number, out.GetNumNeighbors(tile.X, tile.Y),
+			)
+		}
+		out.reveal(out.index(tile.X, tile.Y))
+	}
+	*b = *out
+	return nil
+}
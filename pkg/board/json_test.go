@@ -0,0 +1,82 @@
+package board_test
+
+import (
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for round := 0; round < 50; round++ {
+		b := board.NewBoard(5+rng.Intn(20), 5+rng.Intn(20))
+		if err := b.SpawnMines(rng.Intn(20), rng); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 10; i++ {
+			x, y := rng.Intn(b.GetWidth()), rng.Intn(b.GetHeight())
+			if rng.Intn(2) == 0 {
+				b.Reveal(x, y)
+			} else {
+				b.Flag(x, y, true)
+			}
+		}
+
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out board.Board
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatalf("round %d: %s\n%s", round, err, data)
+		}
+		assertSameBoard(t, b, &out)
+	}
+}
+
+func TestPlayerJSONHidesMines(t *testing.T) {
+	b, err := board.Unmarshal([]byte("4 3\n01F.\n0122\n001*\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := board.MarshalPlayerJSON(b.PlayerView())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"mines"`) {
+		t.Fatalf("expected no mine locations, got %s", data)
+	}
+	if !strings.Contains(string(data), `"remainingMines":1`) {
+		t.Fatalf("expected one remaining mine, got %s", data)
+	}
+	var out board.Board
+	if err := json.Unmarshal(data, &out); err == nil {
+		t.Fatal("expected error decoding player-visible board")
+	}
+}
+
+func TestJSONMines(t *testing.T) {
+	data, err := json.Marshal(board.NewBoard(3, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"mines":[]`) {
+		t.Fatalf("expected an empty mine list, got %s", data)
+	}
+	var out board.Board
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, bad := range []string{
+		`{"width":3,"height":2,"flags":[],"revealed":[]}`,
+		`{"width":3,"height":2,"mines":[],"flags":[],"revealed":[{"x":0,"y":0,"mine":true}]}`,
+	} {
+		if err := json.Unmarshal([]byte(bad), &out); err == nil {
+			t.Fatalf("expected error decoding %s", bad)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package board
+
+import (
+	"fmt"
+
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+// The kind of action taken by a move.
+type MoveKind int
+
+const (
+	// Reveal a single tile.
+	MoveReveal MoveKind = iota
+
+	// Place a flag on a single tile.
+	MoveFlag
+
+	// Remove a flag from a single tile.
+	MoveUnflag
+
+	// Reveal all unflagged neighbors of a revealed tile.
+	MoveChord
+)
+
+func (k MoveKind) String() string {
+	switch k {
+	case MoveReveal:
+		return "reveal"
+	case MoveFlag:
+		return "flag"
+	case MoveUnflag:
+		return "unflag"
+	case MoveChord:
+		return "chord"
+	}
+	return fmt.Sprintf("MoveKind(%d)", int(k))
+}
+
+// Parse a move kind from its name.
+func ParseMoveKind(s string) (MoveKind, error) {
+	for _, k := range []MoveKind{MoveReveal, MoveFlag, MoveUnflag, MoveChord} {
+		if k.String() == s {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown move kind: %s", s)
+}
+
+// Encode a move kind by its name.
+func (k MoveKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Decode a move kind from its name.
+func (k *MoveKind) UnmarshalText(text []byte) error {
+	parsed, err := ParseMoveKind(string(text))
+	if err != nil {
+		return err
+	}
+	*k = parsed
+	return nil
+}
+
+// A proposed action on the board.
+type Move struct {
+	// The kind of action to take.
+	Kind MoveKind
+
+	// The tile to act on.
+	Tile util.Vec
+
+	// A human-readable explanation of why the move was proposed.
+	Reason string
+}
+
+func (m Move) String() string {
+	if m.Reason == "" {
+		return fmt.Sprintf("%s %s", m.Kind, m.Tile)
+	}
+	return fmt.Sprintf("%s %s: %s", m.Kind, m.Tile, m.Reason)
+}
+
+// Take the given move using an actor. Returns whether any mines were revealed.
+func Apply(a Actor, m Move) (hitMine bool) {
+	switch m.Kind {
+	case MoveReveal:
+		return a.Reveal(m.Tile.X, m.Tile.Y)
+	case MoveFlag:
+		a.Flag(m.Tile.X, m.Tile.Y, true)
+	case MoveUnflag:
+		a.Flag(m.Tile.X, m.Tile.Y, false)
+	case MoveChord:
+		return len(a.Chord(m.Tile.X, m.Tile.Y)) > 0
+	default:
+		panic(fmt.Sprintf("unknown move kind %s", m.Kind))
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+// A player-visible position whose mine locations are unknown, such as a puzzle
+// pasted from elsewhere. Implements View, so solvers can analyze it.
+type Position struct {
+	width    int
+	height   int
+	revealed [][]bool
+	flags    [][]bool
+	numbers  [][]int
+	numMines int
+}
+
+// Positions are written in a grid notation, one row per line from the top
+// (highest y) down, with a character per tile:
+//
+//	1-8  revealed, showing its number of neighboring mines
+//	.    revealed, with no neighboring mines (0 is also accepted)
+//	?    unrevealed
+//	F    flagged
+//
+// Blank lines and lines starting with '#' are ignored. For example:
+//
+//	..1?
+//	.12?
+//	.1F?
+
+// Parse a position from grid notation, given the total number of mines
+// (including flagged ones).
+func ParsePosition(s string, numMines int) (*Position, error) {
+	rows := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			rows = append(rows, line)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("empty position")
+	}
+	width, height := len(rows[0]), len(rows)
+	p := &Position{
+		width:    width,
+		height:   height,
+		revealed: util.DArray[bool](width, height),
+		flags:    util.DArray[bool](width, height),
+		numbers:  util.DArray[int](width, height),
+		numMines: numMines,
+	}
+	for i, row := range rows {
+		if len(row) != width {
+			return nil, fmt.Errorf("row %d: expected %d tiles, got %d", i, width, len(row))
+		}
+		y := height - 1 - i
+		for x := 0; x < width; x++ {
+			switch c := row[x]; {
+			case c == '.':
+				p.revealed[x][y] = true
+			case c >= '0' && c <= '8':
+				p.revealed[x][y] = true
+				p.numbers[x][y] = int(c - '0')
+			case c == 'F':
+				p.flags[x][y] = true
+			case c == '?':
+			default:
+				return nil, fmt.Errorf("tile (%d, %d): unknown tile %q", x, y, c)
+			}
+		}
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Check that every number can be satisfied by its neighbors, and that there
+// are enough mines for the flags.
+func (p *Position) validate() error {
+	numFlags := 0
+	for y := 0; y < p.height; y++ {
+		for x := 0; x < p.width; x++ {
+			if p.flags[x][y] {
+				numFlags += 1
+			}
+			if !p.revealed[x][y] {
+				continue
+			}
+			flagged, unknown := 0, 0
+			for _, neighbor := range util.GetNeighbors(x, y, p.width, p.height) {
+				if p.flags[neighbor.X][neighbor.Y] {
+					flagged += 1
+				} else if !p.revealed[neighbor.X][neighbor.Y] {
+					unknown += 1
+				}
+			}
+			if n := p.numbers[x][y]; n < flagged || n > flagged+unknown {
+				return fmt.Errorf("tile (%d, %d): cannot show %d", x, y, n)
+			}
+		}
+	}
+	if numFlags > p.numMines {
+		return fmt.Errorf("%d flags placed but only %d mines", numFlags, p.numMines)
+	}
+	return nil
+}
+
+func (p *Position) GetWidth() int {
+	return p.width
+}
+
+func (p *Position) GetHeight() int {
+	return p.height
+}
+
+func (p *Position) HasReveals() bool {
+	for x := 0; x < p.width; x++ {
+		for y := 0; y < p.height; y++ {
+			if p.revealed[x][y] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *Position) Revealed(x, y int) bool {
+	return p.revealed[x][y]
+}
+
+func (p *Position) HasFlag(x, y int) bool {
+	return p.flags[x][y]
+}
+
+func (p *Position) GetNumNeighbors(x, y int) int {
+	if !p.revealed[x][y] {
+		return -1
+	}
+	return p.numbers[x][y]
+}
+
+func (p *Position) RemainingMines() int {
+	out := p.numMines
+	for x := 0; x < p.width; x++ {
+		for y := 0; y < p.height; y++ {
+			if p.flags[x][y] {
+				out -= 1
+			}
+		}
+	}
+	return out
+}
+
+func (p *Position) String() string {
+	return FormatPosition(p)
+}
+
+// Write the player-visible state of any board in grid notation.
+// Revealed mines are written as unrevealed.
+func FormatPosition(v View) string {
+	out := ""
+	for y := v.GetHeight() - 1; y >= 0; y-- {
+		for x := 0; x < v.GetWidth(); x++ {
+			n := v.GetNumNeighbors(x, y)
+			switch {
+			case v.HasFlag(x, y):
+				out += "F"
+			case !v.Revealed(x, y) || n < 0:
+				out += "?"
+			case n == 0:
+				out += "."
+			default:
+				out += fmt.Sprint(n)
+			}
+		}
+		out += "\n"
+	}
+	return out
+}
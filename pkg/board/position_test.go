@@ -0,0 +1,41 @@
+package board_test
+
+import (
+	"testing"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+)
+
+func TestParsePosition(t *testing.T) {
+	grid := "..1?\n.12?\n.1F?\n"
+	p, err := board.ParsePosition("# example\n"+grid, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.GetWidth() != 4 || p.GetHeight() != 3 {
+		t.Fatalf("expected 4x3 position, got %dx%d", p.GetWidth(), p.GetHeight())
+	}
+	if !p.HasFlag(2, 0) || p.GetNumNeighbors(2, 1) != 2 || p.GetNumNeighbors(3, 0) != -1 {
+		t.Fatal("tiles parsed in the wrong place")
+	}
+	if p.RemainingMines() != 1 {
+		t.Fatalf("expected 1 remaining mine, got %d", p.RemainingMines())
+	}
+	if p.String() != grid {
+		t.Fatalf("expected position to format as\n%s\ngot\n%s", grid, p)
+	}
+}
+
+func TestParsePositionErrors(t *testing.T) {
+	for _, grid := range []string{
+		"",
+		"..\n...\n",
+		".x\n..\n",
+		"4?\n??\n",
+		"1F\nF?\n",
+	} {
+		if _, err := board.ParsePosition(grid, 2); err == nil {
+			t.Fatalf("expected error parsing %q", grid)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package board
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Boards are serialized to a plain-text format. The first line holds the width
+// and height, followed by one row per line from the top (highest y) down, as
+// rendered by textrender. Each tile is a single character:
+//
+//	.    hidden, no mine
+//	*    hidden mine
+//	f    flagged, no mine
+//	F    flagged mine
+//	0-8  revealed, showing its number of neighboring mines
+//	X    revealed mine
+//
+// Blank lines and lines starting with '#' are ignored. For example:
+//
+//	# a 4x3 board with one flagged and one hidden mine
+//	4 3
+//	01F.
+//	0122
+//	001*
+
+// Serialize a board to the plain-text format.
+// Returns err if the board's mines have not been placed yet.
+func Marshal(b *Board) ([]byte, error) {
+	if b.pendingMines > 0 {
+		return nil, fmt.Errorf("cannot marshal board before its mines are placed")
+	}
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%d %d\n", b.width, b.height)
+	for y := b.height - 1; y >= 0; y-- {
+		for x := 0; x < b.width; x++ {
+			out.WriteByte(marshalTile(b, x, y))
+		}
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), nil
+}
+
+func marshalTile(b *Board, x, y int) byte {
+	i := b.index(x, y)
+	switch {
+	case b.revealed.get(i) && b.mines.get(i):
+		return 'X'
+	case b.revealed.get(i):
+		return '0' + b.neighbors[i]
+	case b.flags.get(i) && b.mines.get(i):
+		return 'F'
+	case b.flags.get(i):
+		return 'f'
+	case b.mines.get(i):
+		return '*'
+	}
+	return '.'
+}
+
+// Deserialize a board from the plain-text format.
+func Unmarshal(data []byte) (*Board, error) {
+	lines := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("missing dimensions")
+	}
+
+	dims := strings.Fields(lines[0])
+	if len(dims) != 2 {
+		return nil, fmt.Errorf("invalid dimensions: %s", lines[0])
+	}
+	width, errW := strconv.Atoi(dims[0])
+	height, errH := strconv.Atoi(dims[1])
+	if errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid dimensions: %s", lines[0])
+	}
+	rows := lines[1:]
+	if len(rows) != height {
+		return nil, fmt.Errorf("expected %d rows, got %d", height, len(rows))
+	}
+
+	// Place mines first, so that revealed numbers can be checked.
+	b := NewBoard(width, height)
+	for i, row := range rows {
+		if len(row) != width {
+			return nil, fmt.Errorf("row %d: expected %d tiles, got %d", i, width, len(row))
+		}
+		y := height - 1 - i
+		for x := 0; x < width; x++ {
+			switch c := row[x]; {
+			case c == '*' || c == 'F' || c == 'X':
+				b.PlaceMine(x, y)
+			case c == '.' || c == 'f' || (c >= '0' && c <= '8'):
+			default:
+				return nil, fmt.Errorf("tile (%d, %d): unknown tile %q", x, y, c)
+			}
+		}
+	}
+	for i, row := range rows {
+		y := height - 1 - i
+		for x := 0; x < width; x++ {
+			switch c := row[x]; {
+			case c == 'f' || c == 'F':
+				b.flag(b.index(x, y), true)
+			case c == 'X':
+				b.reveal(b.index(x, y))
+			case c >= '0' && c <= '8':
+				if int(c-'0') != b.GetNumNeighbors(x, y) {
+					return nil, fmt.Errorf(
+						"tile (%d, %d): shows %c but has %d neighboring mines",
+						x, y, c, b.GetNumNeighbors(x, y),
+					)
+				}
+				b.reveal(b.index(x, y))
+			}
+		}
+	}
+	return b, nil
+}
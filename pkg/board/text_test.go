@@ -0,0 +1,88 @@
+package board_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+)
+
+// Check that two boards have identical mines, flags and revealed tiles.
+func assertSameBoard(t *testing.T, a, b *board.Board) {
+	t.Helper()
+	if a.GetWidth() != b.GetWidth() || a.GetHeight() != b.GetHeight() {
+		t.Fatalf(
+			"expected %dx%d board, got %dx%d",
+			a.GetWidth(), a.GetHeight(), b.GetWidth(), b.GetHeight(),
+		)
+	}
+	for x := 0; x < a.GetWidth(); x++ {
+		for y := 0; y < a.GetHeight(); y++ {
+			aMine, aFlag, aRevealed, aNeighbors := a.GetTile(x, y)
+			bMine, bFlag, bRevealed, bNeighbors := b.GetTile(x, y)
+			if aMine != bMine || aFlag != bFlag || aRevealed != bRevealed ||
+				aNeighbors != bNeighbors {
+				t.Fatalf("boards differ at (%d, %d)", x, y)
+			}
+		}
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for round := 0; round < 50; round++ {
+		b := board.NewBoard(5+rng.Intn(20), 5+rng.Intn(20))
+		if err := b.SpawnMines(rng.Intn(20), rng); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 10; i++ {
+			x, y := rng.Intn(b.GetWidth()), rng.Intn(b.GetHeight())
+			if rng.Intn(2) == 0 {
+				b.Reveal(x, y)
+			} else {
+				b.Flag(x, y, true)
+			}
+		}
+
+		data, err := board.Marshal(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := board.Unmarshal(data)
+		if err != nil {
+			t.Fatalf("round %d: %s\n%s", round, err, data)
+		}
+		assertSameBoard(t, b, out)
+		if out.NumMines() != b.NumMines() {
+			t.Fatalf("expected %d mines, got %d", b.NumMines(), out.NumMines())
+		}
+	}
+}
+
+func TestUnmarshalExample(t *testing.T) {
+	b, err := board.Unmarshal([]byte("# comment\n4 3\n01F.\n0122\n001*\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !b.HasMine(2, 2) || !b.HasFlag(2, 2) || !b.HasMine(3, 0) || b.HasFlag(3, 0) {
+		t.Fatal("mines or flags in the wrong place")
+	}
+	if !b.Revealed(2, 0) || b.GetNumNeighbors(2, 0) != 1 || b.Revealed(3, 2) {
+		t.Fatal("revealed tiles in the wrong place")
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"3\n...\n",
+		"2 2\n..\n",
+		"2 2\n..\n...\n",
+		"2 2\n.?\n..\n",
+		"2 2\n1.\n..\n",
+	} {
+		if _, err := board.Unmarshal([]byte(data)); err == nil {
+			t.Fatalf("expected error unmarshalling %q", data)
+		}
+	}
+}
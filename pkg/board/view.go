@@ -0,0 +1,105 @@
+package board
+
+import "github.com/levilutz/minesweeper/pkg/util"
+
+// A read-only view of a board, exposing only what a player could see.
+type View interface {
+	// Get the width of the board.
+	GetWidth() int
+
+	// Get the height of the board.
+	GetHeight() int
+
+	// Check whether the game has any revealed tiles.
+	HasReveals() bool
+
+	// Check whether the given tile is revealed.
+	Revealed(x, y int) bool
+
+	// Check whether the given tile has a flag.
+	HasFlag(x, y int) bool
+
+	// Get the number shown on the given tile, or -1 if it shows no number.
+	GetNumNeighbors(x, y int) int
+
+	// Get the number of mines not yet flagged, assuming all flags are correct.
+	RemainingMines() int
+}
+
+// The actions a player can take on a board.
+type Actor interface {
+	// Reveal a single tile. Returns whether the revealed tile was a mine.
+	Reveal(x, y int) (isMine bool)
+
+	// Set / remove flag for a single tile.
+	Flag(x, y int, flag bool)
+
+	// Reveal all unflagged neighbors of a satisfied number.
+	// Returns the positions of any mines hit.
+	Chord(x, y int) []util.Vec
+}
+
+// A view of a board that hides the location of mines.
+type playerView struct {
+	b *Board
+}
+
+// Get a view of the board that only exposes what a player could see.
+func (b *Board) PlayerView() View {
+	return playerView{b: b}
+}
+
+func (v playerView) GetWidth() int {
+	return v.b.width
+}
+
+func (v playerView) GetHeight() int {
+	return v.b.height
+}
+
+func (v playerView) HasReveals() bool {
+	return v.b.HasReveals()
+}
+
+func (v playerView) Revealed(x, y int) bool {
+	return v.b.Revealed(x, y)
+}
+
+func (v playerView) HasFlag(x, y int) bool {
+	return v.b.HasFlag(x, y)
+}
+
+func (v playerView) GetNumNeighbors(x, y int) int {
+	i := v.b.index(x, y)
+	if !v.b.revealed.get(i) || v.b.mines.get(i) {
+		return -1
+	}
+	return int(v.b.neighbors[i])
+}
+
+func (v playerView) RemainingMines() int {
+	return v.b.NumMines() - v.b.numFlags
+}
+
+// An actor for a board that hides the board itself.
+type playerActor struct {
+	b *Board
+}
+
+// Get an actor for the board, for solvers to take moves with, that does not
+// expose the board's mines.
+func (b *Board) PlayerActor() Actor {
+	return playerActor{b: b}
+}
+
+func (a playerActor) Reveal(x, y int) bool {
+	return a.b.Reveal(x, y)
+}
+
+func (a playerActor) Flag(x, y int, flag bool) {
+	a.b.Flag(x, y, flag)
+}
+
+func (a playerActor) Chord(x, y int) []util.Vec {
+	return a.b.Chord(x, y)
+}
@@ -1,31 +1,62 @@
 package deduce
 
 import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/levilutz/minesweeper/pkg/board"
 	"github.com/levilutz/minesweeper/pkg/infer"
 	"github.com/levilutz/minesweeper/pkg/set"
 	"github.com/levilutz/minesweeper/pkg/util"
 )
 
+// Deduced facts larger than a neighborhood are discarded, as subtracting every
+// combination of neighborhoods from the unknown region would never terminate.
+// Larger facts are kept if they show their tiles are all mines or all empty.
+const maxDerivedTiles = 8
+
+// Groups of facts covering more tiles than this are not deduced from, as every
+// placement of mines in them is tried.
+const maxGroupTiles = 24
+
 // The possible numbers of mines in the given tiles.
 type Fact struct {
-	tiles set.Set[util.Vec]
-	count set.Set[int]
+	tiles set.Bits
+	count set.Frozen[int]
+
+	// The facts a group deduction needed to produce this fact, if any.
+	premises []*Fact
+}
+
+func (f *Fact) String() string {
+	return fmt.Sprintf("%s mines in %s", f.count, f.tiles)
 }
 
 // Whether two facts are equal.
 func (f *Fact) Eq(other *Fact) bool {
-	return set.IsEqual(f.tiles, other.tiles) && set.IsEqual(f.count, other.count)
+	return f.tiles.IsEqual(other.tiles) && f.count.IsEqual(other.count)
 }
 
 // Whether a fact indicates a definite mine.
 func (f *Fact) DefiniteMine() bool {
-	return f.tiles.Size() > 0 && set.IsEqual(f.count, set.NewSet(f.tiles.Size()))
+	return f.tiles.Size() > 0 && f.count.IsEqual(set.FrozenOf(f.tiles.Size()))
 }
 
 // Whether a fact indicates a definite empty square.
 func (f *Fact) DefiniteEmpty() bool {
-	return f.tiles.Size() > 0 && set.IsEqual(f.count, set.NewSet(0))
+	return f.tiles.Size() > 0 && f.count.IsEqual(set.FrozenOf(0))
+}
+
+// Whether a fact narrows its count beyond what its number of tiles allows.
+func (f *Fact) Informative() bool {
+	for c := 0; c <= f.tiles.Size(); c++ {
+		if !f.count.Has(c) {
+			return true
+		}
+	}
+	return false
 }
 
 type Rules struct{}
@@ -37,22 +68,331 @@ func (Rules) Eq(a, b *Fact) bool {
 
 // Perform deduction on a pair of facts.
 func (Rules) DeduceDual(a, b *Fact) []*Fact {
-	if set.IsSubsetStrict(a.tiles, b.tiles) {
+	if a.tiles.IsSubsetStrict(b.tiles) {
 		return deduceDualSubsetStrict(a, b)
-	} else if set.IsSubsetStrict(b.tiles, a.tiles) {
+	} else if b.tiles.IsSubsetStrict(a.tiles) {
 		return deduceDualSubsetStrict(b, a)
 	}
-	return nil
+	return deduceDualOverlap(a, b)
 }
 
 // Perform deduction on a pair of facts, where b is a strict subset of a.
 func deduceDualSubsetStrict(a, b *Fact) []*Fact {
-	return nil
+	return deduceRegions(a, b, a.tiles.Sub(b.tiles), b.tiles, set.NewBits(a.tiles.Width()))
+}
+
+// Perform deduction on a pair of facts, where neither is a strict subset of the other.
+// If the facts have equal tiles, this narrows their count to what both allow.
+func deduceDualOverlap(a, b *Fact) []*Fact {
+	return deduceRegions(
+		a,
+		b,
+		a.tiles.Sub(b.tiles),
+		a.tiles.Intersection(b.tiles),
+		b.tiles.Sub(a.tiles),
+	)
+}
+
+// Given two facts split into regions a-only, shared, and b-only, produce a fact
+// for each region whose possible counts are narrower than its size allows.
+func deduceRegions(a, b *Fact, aOnly, both, bOnly set.Bits) []*Fact {
+	aOnlyCounts := set.NewSet[int]()
+	bothCounts := set.NewSet[int]()
+	bOnlyCounts := set.NewSet[int]()
+	for shared := 0; shared <= both.Size(); shared++ {
+		aRest := restCounts(a.count, shared, aOnly.Size())
+		bRest := restCounts(b.count, shared, bOnly.Size())
+		if len(aRest) == 0 || len(bRest) == 0 {
+			continue
+		}
+		bothCounts.Add(shared)
+		aOnlyCounts.Add(aRest...)
+		bOnlyCounts.Add(bRest...)
+	}
+
+	if bothCounts.Size() == 0 {
+		// The facts contradict each other.
+		return nil
+	}
+
+	out := []*Fact{}
+	for _, region := range []*Fact{
+		{tiles: aOnly, count: set.Freeze(aOnlyCounts)},
+		{tiles: both, count: set.Freeze(bothCounts)},
+		{tiles: bOnly, count: set.Freeze(bOnlyCounts)},
+	} {
+		if region.tiles.Size() > 0 &&
+			(region.tiles.Size() <= maxDerivedTiles || region.DefiniteMine() || region.DefiniteEmpty()) &&
+			region.Informative() {
+			out = append(out, region)
+		}
+	}
+	return out
+}
+
+// Get the possible counts of the rest of a fact, given its count set, the number
+// of mines outside the rest, and the number of tiles in the rest.
+func restCounts(count set.Frozen[int], outside, restSize int) []int {
+	out := []int{}
+	for _, c := range count.AsSortedList(cmp.Compare[int]) {
+		if rest := c - outside; rest >= 0 && rest <= restSize {
+			out = append(out, rest)
+		}
+	}
+	return out
+}
+
+// Perform deduction on a group of facts by trying every placement of mines in
+// their tiles, producing a fact for the tiles that are mines in every placement
+// and one for the tiles that are empty in every placement. Facts larger than a
+// neighborhood only limit the total mines placed, if they cover every tile of
+// the group, such as the remaining mine count. If every placement leaves their
+// other tiles all mines or all empty, a fact for those tiles is produced too.
+// Each fact produced records the facts of the group it needed.
+func (Rules) DeduceGroup(facts []*Fact) []*Fact {
+	members, outer := splitGroup(facts)
+	if len(members) < 2 || len(members)+len(outer) < 3 ||
+		groupTiles(members).Size() > maxGroupTiles {
+		return nil
+	}
+	out := solveGroup(members, outer)
+	for _, f := range out {
+		f.premises = groupPremises(f, append(members, outer...))
+	}
+	return out
+}
+
+// Split a group into the facts whose placements are tried, and the larger facts
+// covering all of their tiles.
+func splitGroup(facts []*Fact) (members, outer []*Fact) {
+	members = groupMembers(facts)
+	tiles := groupTiles(members)
+	for _, f := range facts {
+		if f.tiles.Size() > maxDerivedTiles && f.tiles.IsSubset(tiles) {
+			outer = append(outer, f)
+		}
+	}
+	return members, outer
+}
+
+// Get the tiles of a group's members.
+func groupTiles(members []*Fact) set.Bits {
+	out := set.NewBits(members[0].tiles.Width())
+	for _, f := range members {
+		out = out.Union(f.tiles)
+	}
+	return out
+}
+
+// Get the smallest set of facts found, by dropping one at a time, that group
+// deduction still produces the given fact from.
+func groupPremises(f *Fact, facts []*Fact) []*Fact {
+	used := slices.Clone(facts)
+	for i := 0; i < len(used); {
+		trial := slices.Delete(slices.Clone(used), i, i+1)
+		members, outer := splitGroup(trial)
+		if len(members) > 0 && len(members)+len(outer) == len(trial) && implies(solveGroup(members, outer), f) {
+			used = trial
+		} else {
+			i++
+		}
+	}
+	return used
+}
+
+// Whether any of the facts shows everything the given fact does.
+func implies(facts []*Fact, f *Fact) bool {
+	for _, other := range facts {
+		if other.Eq(f) ||
+			(f.DefiniteMine() && other.DefiniteMine() && f.tiles.IsSubset(other.tiles)) ||
+			(f.DefiniteEmpty() && other.DefiniteEmpty() && f.tiles.IsSubset(other.tiles)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Try every placement of mines in the tiles of a group's members, limited by
+// the outer facts, and produce what every placement agrees on.
+func solveGroup(members, outer []*Fact) []*Fact {
+	width := members[0].tiles.Width()
+	tiles := groupTiles(members)
+	indices := tiles.Indices()
+
+	// For each tile, the members containing it. For each member, the counts it
+	// allows, its mines placed so far, and its tiles not yet placed.
+	memberOf := make([][]int, len(indices))
+	allowed := make([][]bool, len(members))
+	placed := make([]int, len(members))
+	left := make([]int, len(members))
+	for m, f := range members {
+		allowed[m] = make([]bool, f.tiles.Size()+1)
+		for _, c := range f.count.AsList() {
+			if c >= 0 && c <= f.tiles.Size() {
+				allowed[m][c] = true
+			}
+		}
+		left[m] = f.tiles.Size()
+	}
+	for t, i := range indices {
+		v := util.Vec{X: i % width, Y: i / width}
+		for m, f := range members {
+			if f.tiles.Has(v) {
+				memberOf[t] = append(memberOf[t], m)
+			}
+		}
+	}
+	// Whether a member can still reach a count it allows.
+	feasible := func(m int) bool {
+		for c := placed[m]; c <= placed[m]+left[m]; c++ {
+			if allowed[m][c] {
+				return true
+			}
+		}
+		return false
+	}
+
+	// For each outer fact, the size of the rest of its tiles, and the numbers of
+	// mines left for them by the placements so far.
+	restSize := make([]int, len(outer))
+	restMines := make([]set.Set[int], len(outer))
+	for o, f := range outer {
+		restSize[o] = f.tiles.Size() - len(indices)
+		restMines[o] = set.NewSet[int]()
+	}
+	// Whether the rest of an outer fact could still be all mines or all empty.
+	restUndecided := func(o int) bool {
+		return restSize[o] > 0 && restMines[o].Size() <= 1 &&
+			restMines[o].All(func(c int) bool { return c == 0 || c == restSize[o] })
+	}
+	anyRestUndecided := func() bool {
+		for o := range outer {
+			if restUndecided(o) {
+				return true
+			}
+		}
+		return false
+	}
+
+	mines := make([]bool, len(indices))
+	seenMine := make([]bool, len(indices))
+	seenEmpty := make([]bool, len(indices))
+	undecided := len(indices)
+	total := 0
+	var place func(t int)
+	place = func(t int) {
+		if undecided == 0 && !anyRestUndecided() {
+			// Every tile can be either, so nothing more can be learned.
+			return
+		}
+		if t == len(indices) {
+			for o, f := range outer {
+				if !f.count.Any(func(c int) bool { return c-total >= 0 && c-total <= restSize[o] }) {
+					return
+				}
+			}
+			for o, f := range outer {
+				for _, c := range f.count.AsList() {
+					if c-total >= 0 && c-total <= restSize[o] {
+						restMines[o].Add(c - total)
+					}
+				}
+			}
+			for u, mine := range mines {
+				seen := seenEmpty
+				if mine {
+					seen = seenMine
+				}
+				if !seen[u] {
+					seen[u] = true
+					if seenMine[u] && seenEmpty[u] {
+						undecided -= 1
+					}
+				}
+			}
+			return
+		}
+		for _, mine := range []bool{false, true} {
+			ok := true
+			for _, m := range memberOf[t] {
+				left[m] -= 1
+				if mine {
+					placed[m] += 1
+				}
+				ok = ok && feasible(m)
+			}
+			if ok {
+				mines[t] = mine
+				if mine {
+					total += 1
+				}
+				place(t + 1)
+				if mine {
+					total -= 1
+				}
+			}
+			for _, m := range memberOf[t] {
+				left[m] += 1
+				if mine {
+					placed[m] -= 1
+				}
+			}
+		}
+	}
+	place(0)
+
+	definiteMines := set.NewBits(width)
+	definiteEmpty := set.NewBits(width)
+	for t, i := range indices {
+		v := util.Vec{X: i % width, Y: i / width}
+		if seenMine[t] && !seenEmpty[t] {
+			definiteMines.Add(v)
+		} else if seenEmpty[t] && !seenMine[t] {
+			definiteEmpty.Add(v)
+		}
+	}
+	out := []*Fact{}
+	if definiteMines.Size() > 0 {
+		out = append(out, &Fact{tiles: definiteMines, count: set.FrozenOf(definiteMines.Size())})
+	}
+	if definiteEmpty.Size() > 0 {
+		out = append(out, &Fact{tiles: definiteEmpty, count: set.FrozenOf(0)})
+	}
+	for o, f := range outer {
+		if restUndecided(o) && restMines[o].Size() == 1 {
+			rest := f.tiles.Sub(tiles)
+			out = append(out, &Fact{tiles: rest, count: set.Freeze(restMines[o])})
+		}
+	}
+	return out
+}
+
+// Get the facts of a group that group deduction uses.
+func groupMembers(facts []*Fact) []*Fact {
+	out := []*Fact{}
+	for _, f := range facts {
+		if f.tiles.Size() <= maxDerivedTiles {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Get a key that is the same for equal facts. Facts on the same tiles share a
+// key, and are told apart by their counts.
+func (Rules) Key(f *Fact) string {
+	return f.tiles.Key()
+}
+
+// Get the buckets of a fact, which are the indices of its tiles. Facts can only
+// be relevant to each other if they share a tile.
+func (Rules) Buckets(f *Fact) []int {
+	return f.tiles.Indices()
 }
 
 // Whether two facts should be compared.
 func (Rules) Relevant(a, b *Fact) bool {
-	return set.Intersection(a.tiles, b.tiles).Size() > 0
+	return a.tiles.Intersects(b.tiles)
 }
 
 // Whether a fact can generate an action on the board.
@@ -60,72 +400,158 @@ func (Rules) IsConclusion(f *Fact) bool {
 	return f.DefiniteMine() || f.DefiniteEmpty()
 }
 
-// Compute until a single command is run.
-// Returns true if command was run, or false if stuck.
-func Pass(b *board.Board, maxSteps int) bool {
+// Propose moves proven by the first conclusions found, in the order found.
+// Returns no moves if stuck. Does not modify the board.
+func Moves(v board.View, maxSteps int) []board.Move {
+	moves, _ := Analyze(v, maxSteps)
+	return moves
+}
+
+// Like Moves, but also returns the number of deductive steps run.
+func Analyze(v board.View, maxSteps int) ([]board.Move, int) {
 	// Reveal if fresh board.
-	if !b.HasReveals() {
-		b.Reveal(0, 0)
-		return true
+	if !v.HasReveals() {
+		return []board.Move{{
+			Kind:   board.MoveReveal,
+			Tile:   util.Vec{X: 0, Y: 0},
+			Reason: "no tiles revealed yet",
+		}}, 0
 	}
 
 	// Start inference
 	e := infer.NewEngine[*Fact](Rules{})
 
 	// Add the number of total unflagged mines
-	remainingMines := b.UnflaggedMines()
+	remainingMines := v.RemainingMines()
 	unknownTiles := []util.Vec{}
-	for y := 0; y < b.GetSize(); y++ {
-		for x := 0; x < b.GetSize(); x++ {
-			if !b.HasFlag(x, y) && !b.Revealed(x, y) {
+	for y := 0; y < v.GetHeight(); y++ {
+		for x := 0; x < v.GetWidth(); x++ {
+			if !v.HasFlag(x, y) && !v.Revealed(x, y) {
 				unknownTiles = append(unknownTiles, util.Vec{X: x, Y: y})
 			}
 		}
 	}
-	e.AddFact(&Fact{
-		tiles: set.FromList(unknownTiles),
-		count: set.NewSet(remainingMines),
-	})
+	e.AddGiven(&Fact{
+		tiles: set.NewBits(v.GetWidth(), unknownTiles...),
+		count: set.FrozenOf(remainingMines),
+	}, fmt.Sprintf(
+		"%d %s in %d unknown %s",
+		remainingMines, plural(remainingMines, "mine remains", "mines remain"),
+		len(unknownTiles), plural(len(unknownTiles), "tile", "tiles"),
+	))
 
 	// Add a fact for each visible number
-	for y := 0; y < b.GetSize(); y++ {
-		for x := 0; x < b.GetSize(); x++ {
-			if !b.Revealed(x, y) || b.GetNumNeighbors(x, y) == 0 {
+	for y := 0; y < v.GetHeight(); y++ {
+		for x := 0; x < v.GetWidth(); x++ {
+			if !v.Revealed(x, y) || v.GetNumNeighbors(x, y) <= 0 {
 				continue
 			}
 			unknown := []util.Vec{}
-			unfoundMines := b.GetNumNeighbors(x, y)
-			for _, neighbor := range util.GetNeighbors(x, y, b.GetSize()) {
-				if !b.Revealed(neighbor.X, neighbor.Y) &&
-					!b.HasFlag(neighbor.X, neighbor.Y) {
+			unfoundMines := v.GetNumNeighbors(x, y)
+			for _, neighbor := range util.GetNeighbors(x, y, v.GetWidth(), v.GetHeight()) {
+				if !v.Revealed(neighbor.X, neighbor.Y) &&
+					!v.HasFlag(neighbor.X, neighbor.Y) {
 					unknown = append(unknown, util.Vec{X: neighbor.X, Y: neighbor.Y})
 				}
-				if b.HasFlag(neighbor.X, neighbor.Y) {
+				if v.HasFlag(neighbor.X, neighbor.Y) {
 					unfoundMines -= 1
 				}
 			}
 			if len(unknown) > 0 {
-				e.AddFact(&Fact{
-					tiles: set.FromList(unknown),
-					count: set.NewSet(unfoundMines),
-				})
+				description := fmt.Sprintf("(%d, %d) shows %d", x, y, v.GetNumNeighbors(x, y))
+				if flagged := v.GetNumNeighbors(x, y) - unfoundMines; flagged > 0 {
+					description += fmt.Sprintf(" with %d flagged", flagged)
+				}
+				e.AddGiven(&Fact{
+					tiles: set.NewBits(v.GetWidth(), unknown...),
+					count: set.FrozenOf(unfoundMines),
+				}, description)
 			}
 		}
 	}
 
 	e.Deduce(maxSteps, true)
-	if e.HasConclusion() {
-		c := e.Conclusions()[0]
+	out := []board.Move{}
+	seen := set.NewSet[util.Vec]()
+	for _, c := range e.Conclusions() {
+		var kind board.MoveKind
 		if c.DefiniteMine() {
-			vec := c.tiles.AsList()[0]
-			b.Flag(vec.X, vec.Y, true)
+			kind = board.MoveFlag
 		} else if c.DefiniteEmpty() {
-			vec := c.tiles.AsList()[0]
-			b.Reveal(vec.X, vec.Y)
+			kind = board.MoveReveal
 		} else {
 			panic("expected conclusion to indicate definite mine or empty")
 		}
-		return true
+		reason := explain(e, c)
+		for _, vec := range c.tiles.AsList() {
+			if !seen.Has(vec) {
+				seen[vec] = struct{}{}
+				out = append(out, board.Move{Kind: kind, Tile: vec, Reason: reason})
+			}
+		}
 	}
-	return false
+	return out, e.Steps()
+}
+
+// Choose between the singular and plural wording for a number of things.
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// Explain how the engine came to know a fact, as a numbered list of the facts
+// it follows from, starting from those read off the board. Facts too large to
+// list their tiles are summarised.
+func explain(e *infer.Engine[*Fact], f *Fact) string {
+	steps := []string{}
+	numbers := map[*Fact]int{}
+	var visit func(f *Fact) int
+	visit = func(f *Fact) int {
+		if n, ok := numbers[f]; ok {
+			return n
+		}
+		source, _ := e.Source(f)
+		parents := source.Parents
+		if source.Rule == infer.RuleGroup {
+			parents = f.premises
+		}
+		refs := []string{}
+		for _, parent := range parents {
+			refs = append(refs, fmt.Sprintf("[%d]", visit(parent)))
+		}
+		n := len(steps) + 1
+		numbers[f] = n
+		large := f.tiles.Size() > maxDerivedTiles
+		switch {
+		case source.Rule == infer.RuleGiven && large:
+			steps = append(steps, fmt.Sprintf("[%d] %s", n, source.Description))
+		case source.Rule == infer.RuleGiven:
+			steps = append(steps, fmt.Sprintf("[%d] %s: %s", n, source.Description, f))
+		case large:
+			steps = append(steps, fmt.Sprintf(
+				"[%d] from %s: %s mines in %d tiles",
+				n, strings.Join(refs, ", "), f.count, f.tiles.Size(),
+			))
+		default:
+			steps = append(steps, fmt.Sprintf("[%d] from %s: %s", n, strings.Join(refs, ", "), f))
+		}
+		return n
+	}
+	visit(f)
+	return strings.Join(steps, "; ")
+}
+
+// Compute until a single command is run, using an actor such as the board's
+// PlayerActor.
+// Returns the move run and true, or false if stuck. The move's Reason explains
+// how it was deduced.
+func Pass(v board.View, a board.Actor, maxSteps int) (board.Move, bool) {
+	moves := Moves(v, maxSteps)
+	if len(moves) == 0 {
+		return board.Move{}, false
+	}
+	board.Apply(a, moves[0])
+	return moves[0], true
 }
@@ -0,0 +1,134 @@
+package deduce_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/deduce"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+func TestPatterns(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		grid  string
+		mines []util.Vec
+		empty []util.Vec
+	}{
+		{
+			name:  "1-2-1",
+			grid:  "???\n???\n121\n...\n",
+			mines: []util.Vec{{X: 0, Y: 2}, {X: 2, Y: 2}},
+			empty: []util.Vec{{X: 1, Y: 2}},
+		},
+		{
+			name:  "1-2-2-1",
+			grid:  "????\n????\n1221\n....\n",
+			mines: []util.Vec{{X: 1, Y: 2}, {X: 2, Y: 2}},
+			empty: []util.Vec{{X: 0, Y: 2}, {X: 3, Y: 2}},
+		},
+	} {
+		p, err := board.ParsePosition(tc.grid, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := map[util.Vec]board.MoveKind{}
+		for _, v := range tc.mines {
+			expected[v] = board.MoveFlag
+		}
+		for _, v := range tc.empty {
+			expected[v] = board.MoveReveal
+		}
+		moves := deduce.Moves(p, 100000)
+		if len(moves) == 0 {
+			t.Fatalf("%s: expected moves", tc.name)
+		}
+		for _, m := range moves {
+			if kind, ok := expected[m.Tile]; !ok || kind != m.Kind {
+				t.Fatalf("%s: unexpected move %s", tc.name, m)
+			}
+			if !strings.HasPrefix(m.Reason, "[1] (") || !strings.Contains(m.Reason, " shows ") {
+				t.Fatalf("%s: expected reason to start from a number, got %q", tc.name, m.Reason)
+			}
+		}
+	}
+}
+
+func TestRemainingMines(t *testing.T) {
+	// The 1s share their only mine, so with one mine left the interior above
+	// is empty. The interior is larger than a neighborhood.
+	grid := "??\n??\n??\n??\n??\n??\n??\n11\n..\n"
+	p, err := board.ParsePosition(grid, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moves := deduce.Moves(p, 100000)
+	if len(moves) == 0 {
+		t.Fatal("expected moves")
+	}
+	for _, m := range moves {
+		if m.Kind != board.MoveReveal || m.Tile.Y < 3 {
+			t.Fatalf("unexpected move %s", m)
+		}
+		if !strings.Contains(m.Reason, "1 mine remains in 14 unknown tiles") ||
+			strings.Contains(m.Reason, "<0, 8>") {
+			t.Fatalf("expected reason to summarise the remaining mines, got %q", m.Reason)
+		}
+	}
+}
+
+func TestGroupPremises(t *testing.T) {
+	// The numbers alone allow 1 or 2 mines in <7, 5> and <5, 7>, so flagging
+	// both needs the remaining mine count.
+	grid := "....1????\n...12????\n...1F3F??\n.112133??\n.2F2.1F21\n" +
+		".2F2.111.\n.111...11\n..111.12F\n..1F1.1F2\n"
+	p, err := board.ParsePosition(grid, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range deduce.Moves(p, 100000) {
+		if m.Tile == (util.Vec{X: 7, Y: 5}) {
+			if m.Kind != board.MoveFlag || !strings.Contains(m.Reason, "2 mines remain in 12 unknown tiles") {
+				t.Fatalf("expected flag citing the remaining mines, got %s", m)
+			}
+			return
+		}
+	}
+	t.Fatal("expected a move on <7, 5>")
+}
+
+// A board after an opening first reveal in the middle and a few rounds of
+// deduced moves, so that it has a long frontier.
+func midGame(b *testing.B, width, height, numMines int) board.View {
+	out := board.NewBoard(width, height)
+	if err := out.SpawnMinesOnReveal(numMines, rand.New(rand.NewSource(1)), board.FirstClickOpening); err != nil {
+		b.Fatal(err)
+	}
+	out.Reveal(width/2, height/2)
+	for round := 0; round < 3; round++ {
+		for _, move := range deduce.Moves(out.PlayerView(), 100000) {
+			board.Apply(out, move)
+		}
+	}
+	return out.PlayerView()
+}
+
+func benchmarkMoves(b *testing.B, v board.View) {
+	if len(deduce.Moves(v, 100000)) == 0 {
+		b.Fatal("expected moves")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		deduce.Moves(v, 100000)
+	}
+}
+
+func BenchmarkDeduceExpert(b *testing.B) {
+	benchmarkMoves(b, midGame(b, 30, 16, 99))
+}
+
+func BenchmarkDeduceLarge(b *testing.B) {
+	benchmarkMoves(b, midGame(b, 100, 100, 1500))
+}
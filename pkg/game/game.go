@@ -0,0 +1,153 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+)
+
+// Returned for moves taken after the game has ended.
+var ErrGameOver = errors.New("game is over")
+
+// Returned for moves on tiles outside the board.
+var ErrOutOfBounds = errors.New("tile is outside the board")
+
+// The state of a game.
+type State int
+
+const (
+	// No tiles have been revealed yet.
+	NotStarted State = iota
+
+	// Tiles have been revealed, but the game has not ended.
+	Playing
+
+	// Every safe tile has been revealed.
+	Won
+
+	// A mine has been revealed.
+	Lost
+)
+
+func (s State) String() string {
+	switch s {
+	case NotStarted:
+		return "not started"
+	case Playing:
+		return "playing"
+	case Won:
+		return "won"
+	case Lost:
+		return "lost"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Whether the game has ended, won or lost.
+func (s State) Over() bool {
+	return s == Won || s == Lost
+}
+
+// A game played on a board, tracking its state and when it started and ended.
+type Game struct {
+	b     *board.Board
+	state State
+	start time.Time
+	end   time.Time
+}
+
+// Start a game on the given board, from its current position.
+func New(b *board.Board) *Game {
+	g := &Game{b: b}
+	g.update()
+	return g
+}
+
+// Get the board the game is played on.
+func (g *Game) Board() *board.Board {
+	return g.b
+}
+
+// Get the state of the game.
+func (g *Game) State() State {
+	return g.state
+}
+
+// Get when the first move was taken, or the zero time if not started.
+func (g *Game) Start() time.Time {
+	return g.start
+}
+
+// Get when the game ended, or the zero time if it has not.
+func (g *Game) End() time.Time {
+	return g.end
+}
+
+// Get how long the game took, or has taken so far.
+func (g *Game) Duration() time.Duration {
+	if g.start.IsZero() {
+		return 0
+	}
+	if g.end.IsZero() {
+		return time.Since(g.start)
+	}
+	return g.end.Sub(g.start)
+}
+
+// Take a move. Returns whether any mines were revealed, or err if the game is
+// over or the move is outside the board.
+func (g *Game) Apply(m board.Move) (hitMine bool, err error) {
+	if g.state.Over() {
+		return false, ErrGameOver
+	}
+	if !g.b.InBounds(m.Tile.X, m.Tile.Y) {
+		return false, fmt.Errorf("%w: %s", ErrOutOfBounds, m.Tile)
+	}
+	hitMine = board.Apply(g.b, m)
+	g.update()
+	return hitMine, nil
+}
+
+// Undo the last move, which may resume an ended game. Returns whether there was
+// a move to undo.
+func (g *Game) Undo() bool {
+	if !g.b.Undo() {
+		return false
+	}
+	g.update()
+	return true
+}
+
+// Redo the last undone move. Returns whether there was a move to redo.
+func (g *Game) Redo() bool {
+	if !g.b.Redo() {
+		return false
+	}
+	g.update()
+	return true
+}
+
+// Move to the state of the board, keeping the start and end times.
+func (g *Game) update() {
+	now := time.Now()
+	switch {
+	case g.b.HasRevealedMines():
+		g.state = Lost
+	case g.b.Complete():
+		g.state = Won
+	case g.b.HasReveals():
+		g.state = Playing
+	default:
+		g.state = NotStarted
+	}
+	if g.state != NotStarted && g.start.IsZero() {
+		g.start = now
+	}
+	if g.state.Over() && g.end.IsZero() {
+		g.end = now
+	} else if !g.state.Over() {
+		g.end = time.Time{}
+	}
+}
@@ -0,0 +1,78 @@
+package game_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/game"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+func reveal(x, y int) board.Move {
+	return board.Move{Kind: board.MoveReveal, Tile: util.Vec{X: x, Y: y}}
+}
+
+func TestWinAndLose(t *testing.T) {
+	b, err := board.Unmarshal([]byte("3 2\n..*\n...\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := game.New(b)
+	if g.State() != game.NotStarted || !g.Start().IsZero() {
+		t.Fatalf("expected a fresh game, got %s", g.State())
+	}
+
+	if _, err := g.Apply(reveal(0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if g.State() != game.Playing || g.Start().IsZero() || !g.End().IsZero() {
+		t.Fatalf("expected a started game, got %s", g.State())
+	}
+
+	if hit, err := g.Apply(reveal(2, 1)); err != nil || !hit {
+		t.Fatalf("expected to hit a mine, got %v, %v", hit, err)
+	}
+	if g.State() != game.Lost || g.End().IsZero() {
+		t.Fatalf("expected a lost game, got %s", g.State())
+	}
+	if _, err := g.Apply(reveal(2, 0)); !errors.Is(err, game.ErrGameOver) {
+		t.Fatalf("expected moves to be rejected, got %v", err)
+	}
+
+	if !g.Undo() || g.State() != game.Playing || !g.End().IsZero() {
+		t.Fatalf("expected undo to resume the game, got %s", g.State())
+	}
+	if _, err := g.Apply(reveal(2, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if g.State() != game.Won || b.NumRevealedSafe() != 5 {
+		t.Fatalf("expected a won game, got %s", g.State())
+	}
+}
+
+func TestLoadedGameState(t *testing.T) {
+	b, err := board.Unmarshal([]byte("2 1\n1X\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g := game.New(b); g.State() != game.Lost {
+		t.Fatalf("expected a lost game, got %s", g.State())
+	}
+}
+
+func TestOutOfBounds(t *testing.T) {
+	b, err := board.Unmarshal([]byte("3 2\n..*\n...\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := game.New(b)
+	for _, tile := range []util.Vec{{X: -1, Y: 1}, {X: 3, Y: 0}, {X: 0, Y: 2}} {
+		if _, err := g.Apply(reveal(tile.X, tile.Y)); !errors.Is(err, game.ErrOutOfBounds) {
+			t.Fatalf("expected %s to be rejected, got %v", tile, err)
+		}
+	}
+	if b.HasReveals() || g.State() != game.NotStarted {
+		t.Fatal("expected rejected moves to leave the board unchanged")
+	}
+}
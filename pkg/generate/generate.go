@@ -0,0 +1,134 @@
+package generate
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/set"
+	"github.com/levilutz/minesweeper/pkg/solver"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+// The number of mine relocations attempted per tile before giving up.
+const relocationsPerTile = 4
+
+// Generate a board that can be solved from the given first click without any
+// guessing. The returned board has its mines placed but nothing revealed.
+// Returns err if no such board was found, or if the solver hit a mine.
+func NoGuess(width, height, numMines int, first util.Vec, seed int64) (*board.Board, error) {
+	if first.X < 0 || first.X >= width || first.Y < 0 || first.Y >= height {
+		return nil, fmt.Errorf("first click %s is outside the board", first)
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	// Protect the neighborhood of the first click when there's room, so that
+	// it opens an area to start deducing from.
+	protected := set.NewSet(first)
+	neighbors := util.GetNeighbors(first.X, first.Y, width, height)
+	if width*height-len(neighbors)-1 >= numMines {
+		protected = set.Union(protected, set.FromList(neighbors))
+	}
+	if width*height-protected.Size() < numMines {
+		return nil, fmt.Errorf("insufficient empty squares to place this many mines")
+	}
+
+	mines := randomMines(width, height, numMines, protected, rng)
+	for i := 0; i < width*height*relocationsPerTile; i++ {
+		b := build(width, height, mines)
+		solved, err := solve(b, first)
+		if err != nil {
+			return nil, err
+		} else if solved {
+			return build(width, height, mines), nil
+		}
+		if !relocate(b, mines, protected, rng) {
+			// Nowhere left to move mines to, start over.
+			mines = randomMines(width, height, numMines, protected, rng)
+		}
+	}
+	return nil, fmt.Errorf("failed to generate a board without guessing")
+}
+
+// Choose random mine positions, avoiding protected tiles.
+func randomMines(
+	width, height, numMines int, protected set.Set[util.Vec], rng *rand.Rand,
+) set.Set[util.Vec] {
+	open := make([]util.Vec, 0)
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			if !protected.Has(util.Vec{X: x, Y: y}) {
+				open = append(open, util.Vec{X: x, Y: y})
+			}
+		}
+	}
+	return set.FromList(util.Shuffle(open, rng)[:numMines])
+}
+
+// Build a fresh board with mines at the given positions.
+func build(width, height int, mines set.Set[util.Vec]) *board.Board {
+	b := board.NewBoard(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if mines.Has(util.Vec{X: x, Y: y}) {
+				b.PlaceMine(x, y)
+			}
+		}
+	}
+	return b
+}
+
+// Play the board from the first click using only certain moves.
+// Returns whether the board was completed, or err if the solver hit a mine.
+func solve(b *board.Board, first util.Vec) (bool, error) {
+	v := b.PlayerView()
+	b.Reveal(first.X, first.Y)
+	for !b.Complete() {
+		moves := solver.Moves(v)
+		if len(moves) == 0 {
+			return false, nil
+		}
+		for _, move := range moves {
+			if board.Apply(b.PlayerActor(), move) {
+				return false, fmt.Errorf("solver hit a mine with %s", move)
+			}
+		}
+	}
+	return true, nil
+}
+
+// Move a random mine bordering the revealed area of a stuck board to a random
+// tile away from it. Returns false if there was no mine or tile to move.
+func relocate(
+	b *board.Board, mines, protected set.Set[util.Vec], rng *rand.Rand,
+) bool {
+	width, height := b.GetWidth(), b.GetHeight()
+	from := []util.Vec{}
+	to := []util.Vec{}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if b.Revealed(x, y) || b.HasFlag(x, y) {
+				continue
+			}
+			frontier := false
+			for _, neighbor := range util.GetNeighbors(x, y, width, height) {
+				if b.Revealed(neighbor.X, neighbor.Y) {
+					frontier = true
+					break
+				}
+			}
+			tile := util.Vec{X: x, Y: y}
+			if frontier && mines.Has(tile) {
+				from = append(from, tile)
+			} else if !frontier && !mines.Has(tile) && !protected.Has(tile) {
+				to = append(to, tile)
+			}
+		}
+	}
+	if len(from) == 0 || len(to) == 0 {
+		return false
+	}
+	delete(mines, from[rng.Intn(len(from))])
+	mines[to[rng.Intn(len(to))]] = struct{}{}
+	return true
+}
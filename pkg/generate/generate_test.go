@@ -0,0 +1,51 @@
+package generate_test
+
+import (
+	"testing"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/generate"
+	"github.com/levilutz/minesweeper/pkg/solver"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+func TestNoGuessSolvable(t *testing.T) {
+	first := util.Vec{X: 3, Y: 4}
+	for seed := int64(1); seed <= 20; seed++ {
+		b, err := generate.NoGuess(16, 16, 40, first, seed)
+		if err != nil {
+			t.Fatalf("seed %d: %s", seed, err)
+		}
+		if b.NumMines() != 40 {
+			t.Fatalf("seed %d: expected 40 mines, got %d", seed, b.NumMines())
+		}
+		if b.Reveal(first.X, first.Y) {
+			t.Fatalf("seed %d: first click hit a mine", seed)
+		}
+		v := b.PlayerView()
+		for !b.Complete() {
+			moves := solver.Moves(v)
+			if len(moves) == 0 {
+				t.Fatalf("seed %d: solver got stuck", seed)
+			}
+			if board.Apply(b, moves[0]) {
+				t.Fatalf("seed %d: solver hit a mine", seed)
+			}
+		}
+	}
+}
+
+func TestNoGuessReproducible(t *testing.T) {
+	a, errA := generate.NoGuess(9, 9, 10, util.Vec{}, 7)
+	b, errB := generate.NoGuess(9, 9, 10, util.Vec{}, 7)
+	if errA != nil || errB != nil {
+		t.Fatalf("unexpected errors: %v, %v", errA, errB)
+	}
+	for x := 0; x < 9; x++ {
+		for y := 0; y < 9; y++ {
+			if a.HasMine(x, y) != b.HasMine(x, y) {
+				t.Fatalf("boards from equal seeds differ at (%d, %d)", x, y)
+			}
+		}
+	}
+}
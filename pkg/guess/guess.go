@@ -0,0 +1,101 @@
+package guess
+
+import (
+	"fmt"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/probability"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+// Risks within this distance of each other are considered tied.
+const tolerance = 1e-9
+
+// A tile chosen without certainty.
+type Guess struct {
+	// The tile to reveal.
+	Tile util.Vec
+
+	// The probability that the tile contains a mine.
+	Risk float64
+
+	// The estimated probability that the tile is a zero, given it is safe.
+	Opening float64
+}
+
+func (g Guess) String() string {
+	return fmt.Sprintf("%s (%.1f%% risk)", g.Tile, g.Risk*100)
+}
+
+// Get the move revealing the guessed tile.
+func (g Guess) Move() board.Move {
+	return board.Move{
+		Kind:   board.MoveReveal,
+		Tile:   g.Tile,
+		Reason: fmt.Sprintf("guess with %.1f%% risk", g.Risk*100),
+	}
+}
+
+// Choose the unknown tile least likely to contain a mine.
+// Ties are broken by preferring tiles likely to open a zero, then tiles with
+// fewer unknown neighbors (such as corners).
+func Best(v board.View) (Guess, error) {
+	probs, err := probability.Compute(v)
+	if err != nil {
+		return Guess{}, err
+	}
+	width, height := v.GetWidth(), v.GetHeight()
+
+	var best Guess
+	bestUnknown := 0
+	found := false
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if v.Revealed(x, y) || v.HasFlag(x, y) {
+				continue
+			}
+			// Estimate the chance of a zero assuming neighbors are independent.
+			opening := 1.0
+			unknown := 0
+			for _, neighbor := range util.GetNeighbors(x, y, width, height) {
+				if !v.Revealed(neighbor.X, neighbor.Y) {
+					opening *= 1 - probs[neighbor.X][neighbor.Y]
+					unknown += 1
+				}
+			}
+			g := Guess{Tile: util.Vec{X: x, Y: y}, Risk: probs[x][y], Opening: opening}
+			if !found || better(g, unknown, best, bestUnknown) {
+				best, bestUnknown, found = g, unknown, true
+			}
+		}
+	}
+	if !found {
+		return Guess{}, fmt.Errorf("no unknown tiles to guess")
+	}
+	return best, nil
+}
+
+// Whether guess a (with the given unknown neighbors) is preferable to guess b.
+func better(a Guess, aUnknown int, b Guess, bUnknown int) bool {
+	if a.Risk < b.Risk-tolerance {
+		return true
+	} else if a.Risk > b.Risk+tolerance {
+		return false
+	}
+	if a.Opening > b.Opening+tolerance {
+		return true
+	} else if a.Opening < b.Opening-tolerance {
+		return false
+	}
+	return aUnknown < bUnknown
+}
+
+// Reveal the best guess, using an actor such as the board's PlayerActor.
+// Returns the guess made and whether the revealed tile was a mine.
+func Pass(v board.View, a board.Actor) (Guess, bool, error) {
+	g, err := Best(v)
+	if err != nil {
+		return Guess{}, false, err
+	}
+	return g, board.Apply(a, g.Move()), nil
+}
@@ -0,0 +1,71 @@
+package guess_test
+
+import (
+	"testing"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/guess"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+func TestBest(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		grid  string
+		mines int
+		tile  util.Vec
+	}{
+		{
+			// The mine is next to the 1, so the far tile is safe.
+			name:  "lowest risk",
+			grid:  "?1??\n",
+			mines: 1,
+			tile:  util.Vec{X: 3, Y: 0},
+		},
+		{
+			// Both bottom corners are safe, but only <3, 0> may open a zero,
+			// though it has more unknown neighbors.
+			name:  "opening",
+			grid:  "..1?\n112?\n????\n",
+			mines: 2,
+			tile:  util.Vec{X: 3, Y: 0},
+		},
+		{
+			// Every unknown tile is safe and next to a flag, so none can open
+			// a zero, and the corner has the fewest unknown neighbors.
+			name:  "corner",
+			grid:  "???\n?F?\nF??\n",
+			mines: 2,
+			tile:  util.Vec{X: 2, Y: 0},
+		},
+		{
+			// The flag is never guessed, even when every other tile is a mine.
+			name:  "flagged",
+			grid:  "F?\n",
+			mines: 2,
+			tile:  util.Vec{X: 1, Y: 0},
+		},
+	} {
+		p, err := board.ParsePosition(tc.grid, tc.mines)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := guess.Best(p)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		if g.Tile != tc.tile {
+			t.Fatalf("%s: expected to guess %s, got %s", tc.name, tc.tile, g)
+		}
+	}
+}
+
+func TestBestNoUnknown(t *testing.T) {
+	p, err := board.ParsePosition("1F\n", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, err := guess.Best(p); err == nil {
+		t.Fatalf("expected an error with no unknown tiles, got %s", g)
+	}
+}
@@ -1,5 +1,10 @@
 package infer
 
+import (
+	"fmt"
+	"slices"
+)
+
 // Something to provide basic logic functions related to a specific fact type.
 type Logic[T any] interface {
 	// Return whether two facts are equivalent.
@@ -15,6 +20,69 @@ type Logic[T any] interface {
 	IsConclusion(f T) bool
 }
 
+// Logic that can also index facts, so the engine finds duplicates and relevant
+// facts without comparing a new fact against every known one.
+type IndexedLogic[T any] interface {
+	Logic[T]
+
+	// Return a key that is the same for equivalent facts. Facts with equal keys
+	// are still compared with Eq.
+	Key(f T) string
+
+	// Return the buckets a fact belongs to, which are small non-negative numbers
+	// such as tile indices. Facts are only checked with Relevant if they share a
+	// bucket.
+	Buckets(f T) []int
+}
+
+// Logic that can also deduce from a group of three or more facts at once, for
+// conclusions that no pair of them allows.
+type GroupLogic[T any] interface {
+	Logic[T]
+
+	// Return any facts derivable from a group of facts, each relevant to the
+	// first.
+	DeduceGroup(facts []T) []T
+}
+
+// The kind of deduction that made a fact known.
+type Rule int
+
+const (
+	// The fact was given to the engine.
+	RuleGiven Rule = iota
+
+	// The fact was deduced from a pair of facts.
+	RuleDual
+
+	// The fact was deduced from a group of facts.
+	RuleGroup
+)
+
+func (r Rule) String() string {
+	switch r {
+	case RuleGiven:
+		return "given"
+	case RuleDual:
+		return "dual"
+	case RuleGroup:
+		return "group"
+	}
+	return fmt.Sprintf("Rule(%d)", int(r))
+}
+
+// How a fact became known to the engine.
+type Source[T any] struct {
+	// The kind of deduction that made the fact known.
+	Rule Rule
+
+	// The facts it was deduced from, or nil if it was given.
+	Parents []T
+
+	// A description of where a given fact came from, if one was provided.
+	Description string
+}
+
 // An inference engine for a specific type of fact.
 type Engine[T any] struct {
 	// The logic rules used to run the engine.
@@ -23,6 +91,26 @@ type Engine[T any] struct {
 	// The set of known facts.
 	facts []T
 
+	// How each known fact became known.
+	sources []Source[T]
+
+	// The logic's index functions, if it has them.
+	indexed IndexedLogic[T]
+
+	// Known facts by key and by bucket, as indices into facts.
+	byKey    map[string][]int
+	byBucket [][]int
+
+	// The last lookup that found each fact as a candidate, to skip repeats.
+	seenBy     []int
+	numLookups int
+
+	// The logic's group deduction, if it has one.
+	grouped GroupLogic[T]
+
+	// Whether each fact's relevant facts changed since its last group deduction.
+	changed []bool
+
 	// The queue of deductions to run.
 	deduceQ [][]T
 
@@ -31,57 +119,220 @@ type Engine[T any] struct {
 
 	// The conclusion of inference, if hasConclusion = true.
 	conclusions []T
+
+	// The number of deductive steps run.
+	steps int
 }
 
 // Create a new inference engine, given a set of logic functions.
+// If the logic implements IndexedLogic, facts are indexed by key and bucket. If
+// it implements GroupLogic, the engine also deduces from groups of facts.
 func NewEngine[T any](logic Logic[T]) *Engine[T] {
-	return &Engine[T]{
+	e := &Engine[T]{
 		logic:   logic,
 		facts:   []T{},
 		deduceQ: [][]T{},
 	}
+	if indexed, ok := logic.(IndexedLogic[T]); ok {
+		e.indexed = indexed
+		e.byKey = map[string][]int{}
+	}
+	if grouped, ok := logic.(GroupLogic[T]); ok {
+		e.grouped = grouped
+	}
+	return e
 }
 
 // Check if the engine contains a given fact.
 func (e *Engine[T]) HasFact(f T) bool {
-	for _, other := range e.facts {
+	return e.find(f) >= 0
+}
+
+// Get how the engine came to know a fact, if it knows it.
+func (e *Engine[T]) Source(f T) (Source[T], bool) {
+	if i := e.find(f); i >= 0 {
+		return e.sources[i], true
+	}
+	return Source[T]{}, false
+}
+
+// Get the index of a known fact, or -1 if it is not known.
+func (e *Engine[T]) find(f T) int {
+	if e.indexed != nil {
+		return e.findKeyed(f, e.indexed.Key(f))
+	}
+	for i, other := range e.facts {
 		if e.logic.Eq(f, other) {
-			return true
+			return i
+		}
+	}
+	return -1
+}
+
+// Get the index of a known fact with the given key, or -1 if it is not known.
+func (e *Engine[T]) findKeyed(f T, key string) int {
+	for _, i := range e.byKey[key] {
+		if e.logic.Eq(f, e.facts[i]) {
+			return i
 		}
 	}
-	return false
+	return -1
 }
 
 // Inform the inference engine of a new fact.
 func (e *Engine[T]) AddFact(f T) {
+	e.add(f, Source[T]{Rule: RuleGiven})
+}
+
+// Inform the inference engine of a new fact, describing where it came from.
+func (e *Engine[T]) AddGiven(f T, description string) {
+	e.add(f, Source[T]{Rule: RuleGiven, Description: description})
+}
+
+// Add a fact if it is not known, queueing deductions with the relevant facts.
+func (e *Engine[T]) add(f T, source Source[T]) {
+	if e.indexed != nil {
+		e.addIndexed(f, source)
+		return
+	}
 	if e.HasFact(f) {
 		return
 	} else if e.logic.IsConclusion(f) {
 		e.hasConclusion = true
 		e.conclusions = append(e.conclusions, f)
 	}
-	for _, other := range e.facts {
-		if e.logic.Relevant(f, other) {
-			e.deduceQ = append(e.deduceQ, []T{f, other})
-		}
+	e.queueRelevant(f, e.relevant(f, nil))
+	e.facts = append(e.facts, f)
+	e.sources = append(e.sources, source)
+}
+
+// Add a new fact if it is not known, queueing deductions with the relevant
+// facts that share a bucket with it.
+func (e *Engine[T]) addIndexed(f T, source Source[T]) {
+	key := e.indexed.Key(f)
+	if e.findKeyed(f, key) >= 0 {
+		return
+	} else if e.logic.IsConclusion(f) {
+		e.hasConclusion = true
+		e.conclusions = append(e.conclusions, f)
 	}
+	buckets := e.indexed.Buckets(f)
+	e.queueRelevant(f, e.relevant(f, buckets))
+
+	index := len(e.facts)
 	e.facts = append(e.facts, f)
+	e.sources = append(e.sources, source)
+	e.seenBy = append(e.seenBy, 0)
+	e.byKey[key] = append(e.byKey[key], index)
+	for _, bucket := range buckets {
+		if bucket >= len(e.byBucket) {
+			e.byBucket = slices.Grow(e.byBucket, bucket+1-len(e.byBucket))[:bucket+1]
+		}
+		e.byBucket[bucket] = append(e.byBucket[bucket], index)
+	}
+}
+
+// Get the known facts relevant to f, as indices into facts in the order they
+// were added. If the logic is indexed, only facts in the given buckets are
+// checked.
+func (e *Engine[T]) relevant(f T, buckets []int) []int {
+	out := []int{}
+	if e.indexed == nil {
+		for i, other := range e.facts {
+			if e.logic.Relevant(f, other) {
+				out = append(out, i)
+			}
+		}
+		return out
+	}
+	e.numLookups += 1
+	candidates := []int{}
+	for _, bucket := range buckets {
+		if bucket >= len(e.byBucket) {
+			continue
+		}
+		for _, i := range e.byBucket[bucket] {
+			if e.seenBy[i] != e.numLookups {
+				e.seenBy[i] = e.numLookups
+				candidates = append(candidates, i)
+			}
+		}
+	}
+	slices.Sort(candidates)
+	for _, i := range candidates {
+		if e.logic.Relevant(f, e.facts[i]) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// Queue a deduction between a new fact and each relevant known fact. If the
+// logic deduces from groups, those facts' groups have changed.
+func (e *Engine[T]) queueRelevant(f T, relevant []int) {
+	for _, i := range relevant {
+		e.deduceQ = append(e.deduceQ, []T{f, e.facts[i]})
+	}
+	if e.grouped != nil {
+		e.changed = append(e.changed, true)
+		for _, i := range relevant {
+			e.changed[i] = true
+		}
+	}
+}
+
+// Queue a group deduction for each fact whose relevant facts changed since its
+// last one, made of the fact followed by the facts relevant to it. Groups need
+// at least three facts, as pairs are already deduced from.
+func (e *Engine[T]) queueGroups() {
+	for i, f := range e.facts {
+		if !e.changed[i] {
+			continue
+		}
+		e.changed[i] = false
+		var buckets []int
+		if e.indexed != nil {
+			buckets = e.indexed.Buckets(f)
+		}
+		group := []T{f}
+		for _, j := range e.relevant(f, buckets) {
+			if j != i {
+				group = append(group, e.facts[j])
+			}
+		}
+		if len(group) >= 3 {
+			e.deduceQ = append(e.deduceQ, group)
+		}
+	}
 }
 
 // Run the given number of deductive steps, or until a final conclusion is reached.
+// If the logic deduces from groups, groups are queued once no pairs are left.
 func (e *Engine[T]) Deduce(maxSteps int, exitOnFirstConclusion bool) {
 	for r := 0; r < maxSteps; r++ {
-		if (exitOnFirstConclusion && e.hasConclusion) || len(e.deduceQ) == 0 {
+		if exitOnFirstConclusion && e.hasConclusion {
+			return
+		}
+		if len(e.deduceQ) == 0 && e.grouped != nil {
+			e.queueGroups()
+		}
+		if len(e.deduceQ) == 0 {
 			return
 		}
 		next := e.deduceQ[0]
 		e.deduceQ = e.deduceQ[1:]
+		e.steps += 1
 		var out []T = nil
+		source := Source[T]{Parents: next}
 		if len(next) == 2 {
+			source.Rule = RuleDual
 			out = e.logic.DeduceDual(next[0], next[1])
+		} else if len(next) > 2 {
+			source.Rule = RuleGroup
+			out = e.grouped.DeduceGroup(next)
 		}
 		for _, f := range out {
-			e.AddFact(f)
+			e.add(f, source)
 			if exitOnFirstConclusion && e.hasConclusion {
 				return
 			}
@@ -89,6 +340,11 @@ func (e *Engine[T]) Deduce(maxSteps int, exitOnFirstConclusion bool) {
 	}
 }
 
+// Get the number of deductive steps run so far.
+func (e *Engine[T]) Steps() int {
+	return e.steps
+}
+
 // Check whether a final conclusion was found.
 func (e *Engine[T]) HasConclusion() bool {
 	return e.hasConclusion
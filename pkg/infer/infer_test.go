@@ -0,0 +1,88 @@
+package infer_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/levilutz/minesweeper/pkg/infer"
+)
+
+// Facts are numbers, every pair is relevant but deduces nothing, and a group
+// deduces its sum. Sums of at least 6 are conclusions.
+type sumLogic struct{}
+
+func (sumLogic) Eq(a, b int) bool          { return a == b }
+func (sumLogic) DeduceDual(a, b int) []int { return nil }
+func (sumLogic) Relevant(a, b int) bool    { return true }
+func (sumLogic) IsConclusion(f int) bool   { return f >= 6 }
+func (sumLogic) DeduceGroup(facts []int) []int {
+	out := 0
+	for _, f := range facts {
+		out += f
+	}
+	return []int{out}
+}
+
+func TestGroupDeduction(t *testing.T) {
+	e := infer.NewEngine[int](sumLogic{})
+	e.AddFact(2)
+	e.AddFact(4)
+	e.Deduce(100, true)
+	if e.HasConclusion() {
+		t.Fatalf("expected no groups of two facts, got %v", e.Conclusions())
+	}
+
+	e.AddFact(1)
+	e.Deduce(100, true)
+	if !e.HasConclusion() || e.Conclusions()[0] != 7 {
+		t.Fatalf("expected group of three facts to conclude 7, got %v", e.Conclusions())
+	}
+}
+
+func TestSource(t *testing.T) {
+	e := infer.NewEngine[int](sumLogic{})
+	e.AddGiven(1, "one")
+	e.AddGiven(2, "two")
+	e.AddFact(4)
+	e.Deduce(100, true)
+
+	if source, ok := e.Source(1); !ok || source.Rule != infer.RuleGiven || source.Description != "one" {
+		t.Fatalf("unexpected source for given fact: %v", source)
+	}
+	source, ok := e.Source(7)
+	if !ok || source.Rule != infer.RuleGroup || len(source.Parents) != 3 {
+		t.Fatalf("unexpected source for deduced fact: %v", source)
+	}
+	if _, ok := e.Source(3); ok {
+		t.Fatal("expected no source for unknown fact")
+	}
+}
+
+// Facts are numbers keyed by their last digit, so different facts share keys.
+// Every pair deduces its sum up to 20, and 16 is the conclusion.
+type digitLogic struct{}
+
+func (digitLogic) Eq(a, b int) bool        { return a == b }
+func (digitLogic) Relevant(a, b int) bool  { return true }
+func (digitLogic) IsConclusion(f int) bool { return f == 16 }
+func (digitLogic) Key(f int) string        { return strconv.Itoa(f % 10) }
+func (digitLogic) Buckets(f int) []int     { return []int{0} }
+func (digitLogic) DeduceDual(a, b int) []int {
+	if a+b <= 20 {
+		return []int{a + b}
+	}
+	return nil
+}
+
+func TestIndexedSharedKeys(t *testing.T) {
+	e := infer.NewEngine[int](digitLogic{})
+	e.AddFact(3)
+	e.AddFact(13)
+	if !e.HasFact(3) || !e.HasFact(13) || e.HasFact(23) {
+		t.Fatal("expected facts with the same key to be told apart")
+	}
+	e.Deduce(100, true)
+	if !e.HasConclusion() || e.Conclusions()[0] != 16 {
+		t.Fatalf("expected facts with the same key to deduce 16, got %v", e.Conclusions())
+	}
+}
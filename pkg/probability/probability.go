@@ -0,0 +1,307 @@
+package probability
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/set"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+// The number of mines required among a set of frontier tiles.
+type constraint struct {
+	tiles []int
+	mines int
+}
+
+// A connected group of frontier tiles and the constraints over them.
+type component struct {
+	tiles       []util.Vec
+	constraints []constraint
+
+	// Number of consistent layouts, indexed by number of mines.
+	layouts []float64
+
+	// Number of consistent layouts with a mine on each tile, indexed [mines][tile].
+	tileMines [][]float64
+}
+
+// Compute the probability that each tile contains a mine, indexed [x][y].
+// Revealed tiles have probability 0, and flags are assumed to be correct.
+// Returns an error if no mine layout is consistent with the board.
+func Compute(v board.View) ([][]float64, error) {
+	width, height := v.GetWidth(), v.GetHeight()
+	out := util.DArray[float64](width, height)
+
+	unknown := func(tile util.Vec) bool {
+		return !v.Revealed(tile.X, tile.Y) && !v.HasFlag(tile.X, tile.Y)
+	}
+
+	// Gather a constraint for each visible number, indexing frontier tiles.
+	frontier := []util.Vec{}
+	frontierIndex := util.DArray[int](width, height, func() int { return -1 })
+	constraints := []constraint{}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if v.HasFlag(x, y) {
+				out[x][y] = 1
+			}
+			if !v.Revealed(x, y) {
+				continue
+			}
+			c := constraint{mines: v.GetNumNeighbors(x, y)}
+			for _, neighbor := range util.GetNeighbors(x, y, width, height) {
+				if v.HasFlag(neighbor.X, neighbor.Y) {
+					c.mines -= 1
+				} else if unknown(neighbor) {
+					if frontierIndex[neighbor.X][neighbor.Y] == -1 {
+						frontierIndex[neighbor.X][neighbor.Y] = len(frontier)
+						frontier = append(frontier, neighbor)
+					}
+					c.tiles = append(c.tiles, frontierIndex[neighbor.X][neighbor.Y])
+				}
+			}
+			if c.mines < 0 || c.mines > len(c.tiles) {
+				return nil, fmt.Errorf("tile (%d, %d) has inconsistent neighbors", x, y)
+			}
+			if len(c.tiles) > 0 {
+				constraints = append(constraints, c)
+			}
+		}
+	}
+	numInterior := 0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if unknown(util.Vec{X: x, Y: y}) && frontierIndex[x][y] == -1 {
+				numInterior += 1
+			}
+		}
+	}
+
+	components := splitComponents(frontier, constraints)
+	for _, comp := range components {
+		comp.enumerate()
+	}
+
+	// Weight each possible number of frontier mines by the number of ways the
+	// remaining mines can be placed in the interior.
+	remainingMines := v.RemainingMines()
+	total := []float64{1}
+	for _, comp := range components {
+		total = convolve(total, comp.layouts)
+	}
+	lnWeights := make([]float64, len(total))
+	maxLnWeight := math.Inf(-1)
+	for m := range lnWeights {
+		lnWeights[m] = lnChoose(numInterior, remainingMines-m)
+		maxLnWeight = math.Max(maxLnWeight, lnWeights[m])
+	}
+	weight := func(m int) float64 {
+		if m >= len(lnWeights) || math.IsInf(lnWeights[m], -1) {
+			return 0
+		}
+		return math.Exp(lnWeights[m] - maxLnWeight)
+	}
+	norm := 0.0
+	interiorMines := 0.0
+	for m, n := range total {
+		norm += n * weight(m)
+		interiorMines += n * weight(m) * float64(remainingMines-m)
+	}
+	if norm == 0 {
+		return nil, fmt.Errorf("no mine layout is consistent with the board")
+	}
+
+	// Each frontier tile is weighted against the layouts of all other components.
+	for i, comp := range components {
+		others := []float64{1}
+		for j, other := range components {
+			if i != j {
+				others = convolve(others, other.layouts)
+			}
+		}
+		for k, tileMines := range comp.tileMines {
+			kWeight := 0.0
+			for m, n := range others {
+				kWeight += n * weight(k+m)
+			}
+			for t, tile := range comp.tiles {
+				out[tile.X][tile.Y] += tileMines[t] * kWeight / norm
+			}
+		}
+	}
+
+	if numInterior > 0 {
+		interiorProb := interiorMines / norm / float64(numInterior)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if unknown(util.Vec{X: x, Y: y}) && frontierIndex[x][y] == -1 {
+					out[x][y] = interiorProb
+				}
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// Split the frontier into groups of tiles that share no constraints.
+func splitComponents(frontier []util.Vec, constraints []constraint) []*component {
+	parent := util.IndexList(len(frontier))
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for _, c := range constraints {
+		for _, t := range c.tiles[1:] {
+			parent[find(t)] = find(c.tiles[0])
+		}
+	}
+
+	// Tiles are ordered by traversing constraints, so that each constraint
+	// becomes fully assigned as early as possible during enumeration.
+	byRoot := map[int]*component{}
+	localIndex := make([]int, len(frontier))
+	for i := range localIndex {
+		localIndex[i] = -1
+	}
+	out := []*component{}
+	for _, c := range constraints {
+		root := find(c.tiles[0])
+		comp, ok := byRoot[root]
+		if !ok {
+			comp = &component{}
+			byRoot[root] = comp
+			out = append(out, comp)
+		}
+		local := constraint{mines: c.mines, tiles: make([]int, len(c.tiles))}
+		for i, t := range c.tiles {
+			if localIndex[t] == -1 {
+				localIndex[t] = len(comp.tiles)
+				comp.tiles = append(comp.tiles, frontier[t])
+			}
+			local.tiles[i] = localIndex[t]
+		}
+		comp.constraints = append(comp.constraints, local)
+	}
+	return out
+}
+
+// Enumerate all mine layouts of the component consistent with its constraints.
+// Constraints are satisfied in order, choosing which of each one's remaining
+// tiles hold the mines it still needs.
+func (comp *component) enumerate() {
+	numTiles := len(comp.tiles)
+	comp.layouts = make([]float64, numTiles+1)
+	comp.tileMines = make([][]float64, numTiles+1)
+	for k := range comp.tileMines {
+		comp.tileMines[k] = make([]float64, numTiles)
+	}
+
+	// For each constraint, track the mines still needed and tiles still unassigned.
+	tileConstraints := make([][]int, numTiles)
+	needed := make([]int, len(comp.constraints))
+	unassigned := make([]int, len(comp.constraints))
+	for i, c := range comp.constraints {
+		needed[i] = c.mines
+		unassigned[i] = len(c.tiles)
+		for _, t := range c.tiles {
+			tileConstraints[t] = append(tileConstraints[t], i)
+		}
+	}
+
+	// Assign a tile, or take the assignment back with delta -1. Returns whether
+	// every constraint on the tile can still be satisfied.
+	assigned := make([]bool, numTiles)
+	layout := make([]bool, numTiles)
+	assign := func(t int, hasMine bool, delta int) bool {
+		assigned[t] = delta > 0
+		layout[t] = hasMine && delta > 0
+		ok := true
+		for _, c := range tileConstraints[t] {
+			unassigned[c] -= delta
+			if hasMine {
+				needed[c] -= delta
+			}
+			if needed[c] < 0 || needed[c] > unassigned[c] {
+				ok = false
+			}
+		}
+		return ok
+	}
+
+	// Each constraint is on the search stack at most once, so its remaining tiles
+	// and which of them are chosen as mines are kept in buffers of its own.
+	free := make([][]int, len(comp.constraints))
+	chosenMine := make([][]bool, len(comp.constraints))
+	for i, c := range comp.constraints {
+		free[i] = make([]int, 0, len(c.tiles))
+		chosenMine[i] = make([]bool, len(c.tiles))
+	}
+
+	// Every tile is in a constraint, so all are assigned after the last one.
+	var search func(c, mines int)
+	search = func(c, mines int) {
+		if c == len(comp.constraints) {
+			comp.layouts[mines] += 1
+			for i, hasMine := range layout {
+				if hasMine {
+					comp.tileMines[mines][i] += 1
+				}
+			}
+			return
+		}
+		free[c] = free[c][:0]
+		for _, t := range comp.constraints[c].tiles {
+			if !assigned[t] {
+				free[c] = append(free[c], t)
+			}
+		}
+		isMine := chosenMine[c][:len(free[c])]
+		set.EachIndexSubset(len(free[c]), needed[c], func(chosen []int) bool {
+			clear(isMine)
+			for _, j := range chosen {
+				isMine[j] = true
+			}
+			// Checking every tile keeps the counts balanced for taking them back.
+			ok := true
+			for j, t := range free[c] {
+				ok = assign(t, isMine[j], 1) && ok
+			}
+			if ok {
+				search(c+1, mines+len(chosen))
+			}
+			for j, t := range free[c] {
+				assign(t, isMine[j], -1)
+			}
+			return true
+		})
+	}
+	search(0, 0)
+}
+
+// Convolve two distributions over mine counts.
+func convolve(a, b []float64) []float64 {
+	out := make([]float64, len(a)+len(b)-1)
+	for i, x := range a {
+		for j, y := range b {
+			out[i+j] += x * y
+		}
+	}
+	return out
+}
+
+// The natural log of n choose k, or -Inf if there are no such choices.
+func lnChoose(n, k int) float64 {
+	if k < 0 || k > n {
+		return math.Inf(-1)
+	}
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
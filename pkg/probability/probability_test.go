@@ -0,0 +1,99 @@
+package probability_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/probability"
+	"github.com/levilutz/minesweeper/pkg/set"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+// Compute probabilities by checking every possible layout of the unflagged mines.
+func bruteForce(b *board.Board, numMines int) [][]float64 {
+	width, height := b.GetWidth(), b.GetHeight()
+	unknown := []util.Vec{}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if !b.Revealed(x, y) {
+				unknown = append(unknown, util.Vec{X: x, Y: y})
+			}
+		}
+	}
+	counts := util.DArray[float64](width, height)
+	total := 0.0
+	set.EachSubsetOfSize(set.FromList(unknown), numMines, func(layout set.Set[util.Vec]) bool {
+		mines := util.DArray[bool](width, height)
+		for v := range layout {
+			mines[v.X][v.Y] = true
+		}
+		consistent := true
+		for y := 0; y < height && consistent; y++ {
+			for x := 0; x < width && consistent; x++ {
+				if !b.Revealed(x, y) {
+					continue
+				}
+				around := 0
+				for _, neighbor := range util.GetNeighbors(x, y, width, height) {
+					if mines[neighbor.X][neighbor.Y] {
+						around++
+					}
+				}
+				consistent = around == b.GetNumNeighbors(x, y)
+			}
+		}
+		if !consistent {
+			return true
+		}
+		total++
+		for v := range layout {
+			counts[v.X][v.Y]++
+		}
+		return true
+	})
+	for x := range counts {
+		for y := range counts[x] {
+			counts[x][y] /= total
+		}
+	}
+	return counts
+}
+
+func TestComputeMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for round := 0; round < 100; round++ {
+		width, height, numMines := 4+rng.Intn(2), 3+rng.Intn(2), 3+rng.Intn(3)
+		b := board.NewBoard(width, height)
+		for placed := 0; placed < numMines; {
+			x, y := rng.Intn(width), rng.Intn(height)
+			if !b.HasMine(x, y) {
+				b.PlaceMine(x, y)
+				placed++
+			}
+		}
+		for i := 0; i < 3; i++ {
+			x, y := rng.Intn(width), rng.Intn(height)
+			if !b.HasMine(x, y) {
+				b.Reveal(x, y)
+			}
+		}
+
+		got, err := probability.Compute(b.PlayerView())
+		if err != nil {
+			t.Fatalf("round %d: %s", round, err)
+		}
+		want := bruteForce(b, numMines)
+		for x := 0; x < width; x++ {
+			for y := 0; y < height; y++ {
+				if math.Abs(got[x][y]-want[x][y]) > 1e-9 {
+					t.Fatalf(
+						"round %d: tile (%d, %d) expected %f, got %f",
+						round, x, y, want[x][y], got[x][y],
+					)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/game"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+// A move taken during a game, and when it was taken.
+type Step struct {
+	Time   time.Time      `json:"time"`
+	Kind   board.MoveKind `json:"kind"`
+	X      int            `json:"x"`
+	Y      int            `json:"y"`
+	Reason string         `json:"reason,omitempty"`
+}
+
+// Get the move taken by this step.
+func (s Step) Move() board.Move {
+	return board.Move{Kind: s.Kind, Tile: util.Vec{X: s.X, Y: s.Y}, Reason: s.Reason}
+}
+
+func (s Step) String() string {
+	return s.Move().String()
+}
+
+// A recorded game: the starting position with its full mine layout, and every
+// move that changed the board.
+type Replay struct {
+	// The seed the mines were generated from, or 0 if unknown.
+	Seed int64 `json:"seed,omitempty"`
+
+	Start *board.Board `json:"start"`
+	Steps []Step       `json:"steps"`
+}
+
+// Write a replay to a file as JSON.
+func Save(path string, r *Replay) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Read a replay from a JSON file.
+func Load(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Replay
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	if r.Start == nil {
+		return nil, fmt.Errorf("replay has no starting position")
+	}
+	for i, step := range r.Steps {
+		if !r.Start.InBounds(step.X, step.Y) {
+			return nil, fmt.Errorf("step %d: tile (%d, %d) is outside the board", i, step.X, step.Y)
+		}
+	}
+	return &r, nil
+}
+
+// Takes moves in a game, recording them into a replay.
+type Recorder struct {
+	g     *game.Game
+	b     *board.Board
+	seed  int64
+	start *board.Board
+	steps []Step
+
+	// Steps taken back by Undo, most recent last.
+	undone []Step
+}
+
+// Start recording moves in the given game from its current position.
+func NewRecorder(g *game.Game, seed int64) *Recorder {
+	b := g.Board()
+	return &Recorder{g: g, b: b, seed: seed, start: b.Clone()}
+}
+
+// Take a move in the game and record it. Returns whether any mines were
+// revealed, or err if the game is over. Moves that do not change the board are
+// not recorded.
+func (r *Recorder) Apply(m board.Move) (hitMine bool, err error) {
+	before := r.b.NumActions()
+	hitMine, err = r.g.Apply(m)
+	if err != nil {
+		return false, err
+	}
+	if r.b.NumActions() > before {
+		r.steps = append(r.steps, Step{
+			Time:   time.Now(),
+			Kind:   m.Kind,
+			X:      m.Tile.X,
+			Y:      m.Tile.Y,
+			Reason: m.Reason,
+		})
+		r.undone = nil
+	}
+	return hitMine, nil
+}
+
+// Undo the last move in the game, removing it from the recording.
+// Returns whether there was a move to undo.
+func (r *Recorder) Undo() bool {
+	if len(r.steps) == 0 || !r.g.Undo() {
+		return false
+	}
+	r.undone = append(r.undone, r.steps[len(r.steps)-1])
+	r.steps = r.steps[:len(r.steps)-1]
+	return true
+}
+
+// Redo the last undone move, adding it back to the recording.
+// Returns whether there was a move to redo.
+func (r *Recorder) Redo() bool {
+	if len(r.undone) == 0 || !r.g.Redo() {
+		return false
+	}
+	step := r.undone[len(r.undone)-1]
+	step.Time = time.Now()
+	r.undone = r.undone[:len(r.undone)-1]
+	r.steps = append(r.steps, step)
+	return true
+}
+
+// Get the replay recorded so far.
+func (r *Recorder) Replay() *Replay {
+	// Mines are placed by the first reveal, so may be missing from the start.
+	start := r.start.Clone()
+	for x := 0; x < r.b.GetWidth(); x++ {
+		for y := 0; y < r.b.GetHeight(); y++ {
+			if r.b.HasMine(x, y) {
+				start.PlaceMine(x, y)
+			}
+		}
+	}
+	return &Replay{
+		Seed:  r.seed,
+		Start: start,
+		Steps: util.ListCopy(r.steps),
+	}
+}
+
+// Steps through a replay on a board.
+type Player struct {
+	r    *Replay
+	b    *board.Board
+	next int
+
+	// Whether each step taken changed the board, so can be undone.
+	changed []bool
+}
+
+// Start playing a replay from its starting position.
+func NewPlayer(r *Replay) *Player {
+	return &Player{r: r, b: r.Start.Clone()}
+}
+
+// Get the board being played on.
+func (p *Player) Board() *board.Board {
+	return p.b
+}
+
+// Get the number of steps taken so far.
+func (p *Player) Position() int {
+	return p.next
+}
+
+// Whether every step has been taken.
+func (p *Player) Done() bool {
+	return p.next >= len(p.r.Steps)
+}
+
+// Take the next step. Returns the step, or false if there are none left.
+func (p *Player) Forward() (Step, bool) {
+	if p.Done() {
+		return Step{}, false
+	}
+	step := p.r.Steps[p.next]
+	before := p.b.NumActions()
+	board.Apply(p.b, step.Move())
+	p.changed = append(p.changed, p.b.NumActions() > before)
+	p.next += 1
+	return step, true
+}
+
+// Take back the last step. Returns whether there was a step to take back.
+func (p *Player) Back() bool {
+	if p.next == 0 {
+		return false
+	}
+	if p.changed[p.next-1] {
+		p.b.Undo()
+	}
+	p.changed = p.changed[:p.next-1]
+	p.next -= 1
+	return true
+}
@@ -0,0 +1,79 @@
+package replay_test
+
+import (
+	"bytes"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/game"
+	"github.com/levilutz/minesweeper/pkg/replay"
+	"github.com/levilutz/minesweeper/pkg/solver"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+// Encode a board in the text format, for comparison.
+func marshal(t *testing.T, b *board.Board) []byte {
+	t.Helper()
+	data, err := board.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestRecordAndPlay(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	b := board.NewBoard(16, 16)
+	if err := b.SpawnMinesOnReveal(40, rng, board.FirstClickOpening); err != nil {
+		t.Fatal(err)
+	}
+	g := game.New(b)
+	rec := replay.NewRecorder(g, 1)
+	positions := [][]byte{}
+	for turn := 0; turn < 100 && !g.State().Over(); turn++ {
+		moves := solver.Moves(b.PlayerView())
+		if len(moves) == 0 {
+			// Guess a hidden tile.
+			x, y := rng.Intn(16), rng.Intn(16)
+			moves = []board.Move{{Kind: board.MoveReveal, Tile: util.Vec{X: x, Y: y}}}
+		}
+		if _, err := rec.Apply(moves[0]); err != nil {
+			t.Fatal(err)
+		}
+		// Moves that change nothing are not recorded as steps.
+		if len(rec.Replay().Steps) > len(positions) {
+			positions = append(positions, marshal(t, b))
+		}
+	}
+	if rec.Undo() {
+		positions = positions[:len(positions)-1]
+	}
+
+	path := filepath.Join(t.TempDir(), "replay.json")
+	if err := replay.Save(path, rec.Replay()); err != nil {
+		t.Fatal(err)
+	}
+	r, err := replay.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := replay.NewPlayer(r)
+	start := marshal(t, p.Board())
+	for i := 0; !p.Done(); i++ {
+		p.Forward()
+		if !bytes.Equal(marshal(t, p.Board()), positions[i]) {
+			t.Fatalf("position differs after step %d", i)
+		}
+	}
+	if !bytes.Equal(marshal(t, p.Board()), marshal(t, b)) {
+		t.Fatal("expected replay to end at the recorded position")
+	}
+	for p.Back() {
+	}
+	if p.Position() != 0 || !bytes.Equal(marshal(t, p.Board()), start) {
+		t.Fatal("expected stepping back to return to the start")
+	}
+}
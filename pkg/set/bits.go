@@ -0,0 +1,189 @@
+package set
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+// A set of tiles on a board of a fixed width, stored as bits indexed by
+// y*width+x. Operations on two sets expect them to share a width.
+type Bits struct {
+	width int
+	words []uint64
+}
+
+// Create a tile set for a board of the given width.
+func NewBits(width int, tiles ...util.Vec) Bits {
+	out := Bits{width: width}
+	for _, tile := range tiles {
+		out.Add(tile)
+	}
+	return out
+}
+
+// Create a tile set for a board of the given width from a map-based set.
+func BitsFromSet(width int, s Set[util.Vec]) Bits {
+	out := Bits{width: width}
+	for tile := range s {
+		out.Add(tile)
+	}
+	return out
+}
+
+// Get the width of the board the tiles are on.
+func (s Bits) Width() int {
+	return s.width
+}
+
+// Add a tile to the set.
+func (s *Bits) Add(v util.Vec) {
+	i := v.Y*s.width + v.X
+	for i>>6 >= len(s.words) {
+		s.words = append(s.words, 0)
+	}
+	s.words[i>>6] |= 1 << (uint(i) & 63)
+}
+
+// Remove a tile from the set.
+func (s *Bits) Remove(v util.Vec) {
+	i := v.Y*s.width + v.X
+	if i>>6 < len(s.words) {
+		s.words[i>>6] &^= 1 << (uint(i) & 63)
+	}
+}
+
+// Return whether the set has the given tile.
+func (s Bits) Has(v util.Vec) bool {
+	i := v.Y*s.width + v.X
+	return i>>6 < len(s.words) && s.words[i>>6]&(1<<(uint(i)&63)) != 0
+}
+
+// Get the size of the set.
+func (s Bits) Size() int {
+	out := 0
+	for _, w := range s.words {
+		out += bits.OnesCount64(w)
+	}
+	return out
+}
+
+// Convert the set to a list, ordered by row then column.
+func (s Bits) AsList() []util.Vec {
+	indices := s.Indices()
+	out := make([]util.Vec, len(indices))
+	for j, i := range indices {
+		out[j] = util.Vec{X: i % s.width, Y: i / s.width}
+	}
+	return out
+}
+
+// Get the indices (y*width+x) of the tiles in the set, in order.
+func (s Bits) Indices() []int {
+	out := make([]int, 0, s.Size())
+	for wi, w := range s.words {
+		for w != 0 {
+			out = append(out, wi<<6+bits.TrailingZeros64(w))
+			w &= w - 1
+		}
+	}
+	return out
+}
+
+// Get a string that is the same for equal sets, for use as a map key.
+func (s Bits) Key() string {
+	n := len(s.words)
+	for n > 0 && s.words[n-1] == 0 {
+		n--
+	}
+	out := make([]byte, 0, n*8)
+	for _, w := range s.words[:n] {
+		out = binary.LittleEndian.AppendUint64(out, w)
+	}
+	return string(out)
+}
+
+// Convert the set to a map-based set.
+func (s Bits) AsSet() Set[util.Vec] {
+	return FromList(s.AsList())
+}
+
+func (s Bits) String() string {
+	out := "("
+	for i, v := range s.AsList() {
+		if i > 0 {
+			out += ", "
+		}
+		out += fmt.Sprint(v)
+	}
+	return out + ")"
+}
+
+// Get the set of tiles in either set.
+func (s Bits) Union(o Bits) Bits {
+	long, short := s.words, o.words
+	if len(long) < len(short) {
+		long, short = short, long
+	}
+	out := Bits{width: s.width, words: make([]uint64, len(long))}
+	copy(out.words, long)
+	for i, w := range short {
+		out.words[i] |= w
+	}
+	return out
+}
+
+// Get the set of tiles in both sets.
+func (s Bits) Intersection(o Bits) Bits {
+	out := Bits{width: s.width, words: make([]uint64, min(len(s.words), len(o.words)))}
+	for i := range out.words {
+		out.words[i] = s.words[i] & o.words[i]
+	}
+	return out
+}
+
+// Subtract set o from this set.
+func (s Bits) Sub(o Bits) Bits {
+	out := Bits{width: s.width, words: make([]uint64, len(s.words))}
+	copy(out.words, s.words)
+	for i := 0; i < len(out.words) && i < len(o.words); i++ {
+		out.words[i] &^= o.words[i]
+	}
+	return out
+}
+
+// Return whether the sets share any tiles.
+func (s Bits) Intersects(o Bits) bool {
+	for i := 0; i < len(s.words) && i < len(o.words); i++ {
+		if s.words[i]&o.words[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Return whether o is a non-strict subset of this set.
+func (s Bits) IsSubset(o Bits) bool {
+	for i, w := range o.words {
+		var have uint64
+		if i < len(s.words) {
+			have = s.words[i]
+		}
+		if w&^have != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Whether the sets are equal.
+func (s Bits) IsEqual(o Bits) bool {
+	return s.IsSubset(o) && o.IsSubset(s)
+}
+
+// Return whether o is a strict subset of this set.
+func (s Bits) IsSubsetStrict(o Bits) bool {
+	return s.IsSubset(o) && !o.IsSubset(s)
+}
@@ -0,0 +1,148 @@
+package set_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/levilutz/minesweeper/pkg/set"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+// Pick up to n random tiles on a width x height board.
+func randomTiles(rng *rand.Rand, width, height, n int) set.Set[util.Vec] {
+	out := set.NewSet[util.Vec]()
+	for i := 0; i < n; i++ {
+		out[util.Vec{X: rng.Intn(width), Y: rng.Intn(height)}] = struct{}{}
+	}
+	return out
+}
+
+// Pick a random tile's neighborhood on a width x height board, like the tile
+// sets the solvers build from visible numbers.
+func randomNeighborhood(rng *rand.Rand, width, height int) set.Set[util.Vec] {
+	return set.FromList(util.GetNeighbors(rng.Intn(width), rng.Intn(height), width, height))
+}
+
+func TestBitsMatchesSet(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for round := 0; round < 1000; round++ {
+		width, height := 1+rng.Intn(40), 1+rng.Intn(20)
+		a := randomTiles(rng, width, height, rng.Intn(10))
+		b := randomTiles(rng, width, height, rng.Intn(10))
+		if rng.Intn(4) == 0 {
+			b = set.Union(a, b)
+		}
+		aBits, bBits := set.BitsFromSet(width, a), set.BitsFromSet(width, b)
+
+		if aBits.Size() != a.Size() || !set.IsEqual(aBits.AsSet(), a) {
+			t.Fatalf("expected %s, got %s", a, aBits)
+		}
+		if got := aBits.Union(bBits).AsSet(); !set.IsEqual(got, set.Union(a, b)) {
+			t.Fatalf("%s | %s: got %s", a, b, got)
+		}
+		if got := aBits.Intersection(bBits).AsSet(); !set.IsEqual(got, set.Intersection(a, b)) {
+			t.Fatalf("%s & %s: got %s", a, b, got)
+		}
+		if got := aBits.Sub(bBits).AsSet(); !set.IsEqual(got, set.Sub(a, b)) {
+			t.Fatalf("%s - %s: got %s", a, b, got)
+		}
+		if aBits.Intersects(bBits) != (set.Intersection(a, b).Size() > 0) {
+			t.Fatalf("%s, %s: wrong intersects", a, b)
+		}
+		if aBits.IsSubset(bBits) != set.IsSubset(a, b) ||
+			bBits.IsSubset(aBits) != set.IsSubset(b, a) ||
+			bBits.IsSubsetStrict(aBits) != set.IsSubsetStrict(b, a) ||
+			aBits.IsEqual(bBits) != set.IsEqual(a, b) {
+			t.Fatalf("%s, %s: wrong subset relation", a, b)
+		}
+		if (aBits.Key() == bBits.Key()) != set.IsEqual(a, b) {
+			t.Fatalf("%s, %s: wrong key equality", a, b)
+		}
+	}
+}
+
+func TestBitsAsListOrdered(t *testing.T) {
+	s := set.NewBits(3, util.Vec{X: 2, Y: 1}, util.Vec{X: 0, Y: 2}, util.Vec{X: 1, Y: 0})
+	s.Add(util.Vec{X: 0, Y: 1})
+	s.Remove(util.Vec{X: 0, Y: 2})
+	if got := s.String(); got != "(<1, 0>, <0, 1>, <2, 1>)" {
+		t.Fatalf("unexpected order: %s", got)
+	}
+}
+
+// Pairs of neighborhood-sized tile sets on an expert board.
+func benchPairs() [][2]set.Set[util.Vec] {
+	rng := rand.New(rand.NewSource(1))
+	out := make([][2]set.Set[util.Vec], 256)
+	for i := range out {
+		a := randomNeighborhood(rng, 30, 16)
+		b := randomNeighborhood(rng, 30, 16)
+		if i%2 == 0 {
+			b = set.Union(a, b)
+		}
+		out[i] = [2]set.Set[util.Vec]{a, b}
+	}
+	return out
+}
+
+func benchBitsPairs() [][2]set.Bits {
+	out := [][2]set.Bits{}
+	for _, pair := range benchPairs() {
+		out = append(out, [2]set.Bits{set.BitsFromSet(30, pair[0]), set.BitsFromSet(30, pair[1])})
+	}
+	return out
+}
+
+func BenchmarkSetIsSubset(b *testing.B) {
+	pairs := benchPairs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pair := pairs[i%len(pairs)]
+		set.IsSubset(pair[1], pair[0])
+	}
+}
+
+func BenchmarkBitsIsSubset(b *testing.B) {
+	pairs := benchBitsPairs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pair := pairs[i%len(pairs)]
+		pair[1].IsSubset(pair[0])
+	}
+}
+
+func BenchmarkSetSub(b *testing.B) {
+	pairs := benchPairs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pair := pairs[i%len(pairs)]
+		set.Sub(pair[1], pair[0])
+	}
+}
+
+func BenchmarkBitsSub(b *testing.B) {
+	pairs := benchBitsPairs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pair := pairs[i%len(pairs)]
+		pair[1].Sub(pair[0])
+	}
+}
+
+func BenchmarkSetIntersection(b *testing.B) {
+	pairs := benchPairs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pair := pairs[i%len(pairs)]
+		set.Intersection(pair[0], pair[1])
+	}
+}
+
+func BenchmarkBitsIntersection(b *testing.B) {
+	pairs := benchBitsPairs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pair := pairs[i%len(pairs)]
+		pair[0].Intersection(pair[1])
+	}
+}
@@ -0,0 +1,60 @@
+package set
+
+// A set that cannot be modified once created.
+type Frozen[K comparable] struct {
+	s Set[K]
+}
+
+// Create a frozen set of the given values.
+func FrozenOf[K comparable](vals ...K) Frozen[K] {
+	return Frozen[K]{s: FromList(vals)}
+}
+
+// Create a frozen copy of a set.
+func Freeze[K comparable](s Set[K]) Frozen[K] {
+	return Frozen[K]{s: Union(s)}
+}
+
+// Get a modifiable copy of the set.
+func (f Frozen[K]) Thaw() Set[K] {
+	return Union(f.s)
+}
+
+// Convert the set to a list.
+func (f Frozen[K]) AsList() []K {
+	return f.s.AsList()
+}
+
+// Convert the set to a list, sorted by the given comparison function.
+func (f Frozen[K]) AsSortedList(compare func(a, b K) int) []K {
+	return f.s.AsSortedList(compare)
+}
+
+// Get the size of the set.
+func (f Frozen[K]) Size() int {
+	return len(f.s)
+}
+
+// Return whether the set has the given value.
+func (f Frozen[K]) Has(v K) bool {
+	return f.s.Has(v)
+}
+
+// Return whether the given condition is true for all set elements.
+func (f Frozen[K]) All(fn func(K) bool) bool {
+	return f.s.All(fn)
+}
+
+// Return whether the given condition is true for any set elements.
+func (f Frozen[K]) Any(fn func(K) bool) bool {
+	return f.s.Any(fn)
+}
+
+// Whether two frozen sets are equal.
+func (f Frozen[K]) IsEqual(o Frozen[K]) bool {
+	return IsEqual(f.s, o.s)
+}
+
+func (f Frozen[K]) String() string {
+	return f.s.String()
+}
@@ -1,6 +1,11 @@
 package set
 
-import "fmt"
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
 
 // A set of distinct elements.
 type Set[K comparable] map[K]struct{}
@@ -29,11 +34,32 @@ func (s Set[K]) AsList() []K {
 	return out
 }
 
+// Convert the set to a list, sorted by the given comparison function.
+func (s Set[K]) AsSortedList(compare func(a, b K) int) []K {
+	out := s.AsList()
+	slices.SortFunc(out, compare)
+	return out
+}
+
 // Get the size of the set.
 func (s Set[K]) Size() int {
 	return len(s)
 }
 
+// Add values to the set in place.
+func (s Set[K]) Add(vals ...K) {
+	for _, v := range vals {
+		s[v] = struct{}{}
+	}
+}
+
+// Remove values from the set in place.
+func (s Set[K]) Remove(vals ...K) {
+	for _, v := range vals {
+		delete(s, v)
+	}
+}
+
 // Return whether the set has the given value.
 func (s Set[K]) Has(v K) bool {
 	_, ok := s[v]
@@ -62,17 +88,33 @@ func (s Set[K]) Any(fn func(K) bool) bool {
 
 func (s Set[K]) String() string {
 	out := "("
-	i := 0
-	for v := range s {
-		out += fmt.Sprint(v)
-		if i != len(s)-1 {
+	for i, v := range s.AsSortedList(compareAny[K]) {
+		if i > 0 {
 			out += ", "
 		}
-		i++
+		out += fmt.Sprint(v)
 	}
 	return out + ")"
 }
 
+// Order set elements by their Compare method or natural order if they have one,
+// otherwise by how they print.
+func compareAny[K comparable](a, b K) int {
+	switch a := any(a).(type) {
+	case interface{ Compare(K) int }:
+		return a.Compare(b)
+	case int:
+		return cmp.Compare(a, any(b).(int))
+	case int64:
+		return cmp.Compare(a, any(b).(int64))
+	case float64:
+		return cmp.Compare(a, any(b).(float64))
+	case string:
+		return cmp.Compare(a, any(b).(string))
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
 // Get the set that's the union of all provided.
 func Union[K comparable](sets ...Set[K]) Set[K] {
 	out := make(map[K]struct{})
@@ -129,3 +171,87 @@ func IsEqual[K comparable](a, b Set[K]) bool {
 func IsSubsetStrict[K comparable](a, b Set[K]) bool {
 	return IsSubset(a, b) && !IsSubset(b, a)
 }
+
+// Get the set of values in exactly one of a and b.
+func SymmetricDifference[K comparable](a, b Set[K]) Set[K] {
+	out := Sub(a, b)
+	for v := range b {
+		if !a.Has(v) {
+			out[v] = struct{}{}
+		}
+	}
+	return out
+}
+
+// Return whether a and b have no values in common.
+func IsDisjoint[K comparable](a, b Set[K]) bool {
+	return IntersectionSize(a, b) == 0
+}
+
+// Get the size of the intersection of a and b, without building it.
+func IntersectionSize[K comparable](a, b Set[K]) int {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	out := 0
+	for v := range a {
+		if b.Has(v) {
+			out += 1
+		}
+	}
+	return out
+}
+
+// Call fn with each subset of s of the given size, until it returns false.
+// Subsets are enumerated in a fixed order, and fn may keep or modify them.
+func EachSubsetOfSize[K comparable](s Set[K], size int, fn func(Set[K]) bool) {
+	vals := s.AsSortedList(compareAny[K])
+	EachIndexSubset(len(vals), size, func(chosen []int) bool {
+		subset := make(Set[K], size)
+		for _, i := range chosen {
+			subset[vals[i]] = struct{}{}
+		}
+		return fn(subset)
+	})
+}
+
+// Call fn with the indices of each subset of size k of n items, in increasing
+// order, until it returns false. Nothing is allocated per subset, as the slice
+// is reused between calls, so fn must copy it to keep it.
+func EachIndexSubset(n, k int, fn func(chosen []int) bool) {
+	if k < 0 || k > n {
+		return
+	}
+	chosen := make([]int, k)
+	for i := range chosen {
+		chosen[i] = i
+	}
+	for {
+		if !fn(chosen) {
+			return
+		}
+		// Advance the rightmost index that has room, and reset those after it.
+		i := k - 1
+		for i >= 0 && chosen[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return
+		}
+		chosen[i] += 1
+		for j := i + 1; j < k; j++ {
+			chosen[j] = chosen[j-1] + 1
+		}
+	}
+}
+
+// Call fn with each subset of s, smallest first, until it returns false.
+func EachSubset[K comparable](s Set[K], fn func(Set[K]) bool) {
+	stopped := false
+	for size := 0; size <= len(s) && !stopped; size++ {
+		EachSubsetOfSize(s, size, func(subset Set[K]) bool {
+			stopped = !fn(subset)
+			return !stopped
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package set_test
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+
+	"github.com/levilutz/minesweeper/pkg/set"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
+
+func TestAsSortedList(t *testing.T) {
+	s := set.NewSet(5, 3, 10, 1)
+	if got := s.AsSortedList(cmp.Compare[int]); !slices.Equal(got, []int{1, 3, 5, 10}) {
+		t.Fatalf("unexpected order: %v", got)
+	}
+	tiles := set.NewSet(util.Vec{X: 2, Y: 1}, util.Vec{X: 0, Y: 2}, util.Vec{X: 1, Y: 1})
+	want := []util.Vec{{X: 1, Y: 1}, {X: 2, Y: 1}, {X: 0, Y: 2}}
+	if got := tiles.AsSortedList(util.Vec.Compare); !slices.Equal(got, want) {
+		t.Fatalf("unexpected order: %v", got)
+	}
+}
+
+func TestStringSorted(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		if got := set.NewSet(10, 2, 1).String(); got != "(1, 2, 10)" {
+			t.Fatalf("unexpected string: %s", got)
+		}
+		tiles := set.NewSet(util.Vec{X: 1, Y: 1}, util.Vec{X: 3, Y: 0})
+		if got := tiles.String(); got != "(<3, 0>, <1, 1>)" {
+			t.Fatalf("unexpected string: %s", got)
+		}
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	s := set.NewSet[int]()
+	s.Add(1, 2, 3, 2)
+	s.Remove(2, 4)
+	if !set.IsEqual(s, set.NewSet(1, 3)) {
+		t.Fatalf("unexpected set: %s", s)
+	}
+}
+
+func TestSymmetricDifference(t *testing.T) {
+	a, b := set.NewSet(1, 2, 3), set.NewSet(3, 4)
+	if got := set.SymmetricDifference(a, b); !set.IsEqual(got, set.NewSet(1, 2, 4)) {
+		t.Fatalf("unexpected difference: %s", got)
+	}
+	if got := set.SymmetricDifference(a, a); got.Size() != 0 {
+		t.Fatalf("expected empty difference, got %s", got)
+	}
+}
+
+func TestIntersectionSize(t *testing.T) {
+	cases := []struct {
+		a, b     set.Set[int]
+		size     int
+		disjoint bool
+	}{
+		{set.NewSet(1, 2, 3), set.NewSet(2, 3, 4, 5), 2, false},
+		{set.NewSet(1, 2), set.NewSet(3), 0, true},
+		{set.NewSet[int](), set.NewSet(1), 0, true},
+	}
+	for _, c := range cases {
+		if got := set.IntersectionSize(c.a, c.b); got != c.size {
+			t.Fatalf("%s & %s: expected size %d, got %d", c.a, c.b, c.size, got)
+		}
+		if got := set.IsDisjoint(c.a, c.b); got != c.disjoint {
+			t.Fatalf("%s, %s: expected disjoint %v, got %v", c.a, c.b, c.disjoint, got)
+		}
+	}
+}
+
+func TestEachSubsetOfSize(t *testing.T) {
+	s := set.NewSet(1, 2, 3, 4, 5)
+	for size, want := range []int{1, 5, 10, 10, 5, 1} {
+		seen := set.NewSet[string]()
+		set.EachSubsetOfSize(s, size, func(subset set.Set[int]) bool {
+			if subset.Size() != size || !set.IsSubset(s, subset) {
+				t.Fatalf("unexpected subset %s of size %d", subset, size)
+			}
+			seen.Add(subset.String())
+			return true
+		})
+		if seen.Size() != want {
+			t.Fatalf("expected %d subsets of size %d, got %d", want, size, seen.Size())
+		}
+	}
+	set.EachSubsetOfSize(s, 6, func(set.Set[int]) bool {
+		t.Fatal("expected no subsets larger than the set")
+		return false
+	})
+}
+
+func TestEachIndexSubset(t *testing.T) {
+	got := [][]int{}
+	set.EachIndexSubset(4, 2, func(chosen []int) bool {
+		got = append(got, slices.Clone(chosen))
+		return true
+	})
+	want := [][]int{{0, 1}, {0, 2}, {0, 3}, {1, 2}, {1, 3}, {2, 3}}
+	if !slices.EqualFunc(got, want, slices.Equal[[]int]) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestEachSubset(t *testing.T) {
+	s := set.NewSet(1, 2, 3, 4)
+	sizes := []int{}
+	set.EachSubset(s, func(subset set.Set[int]) bool {
+		sizes = append(sizes, subset.Size())
+		return true
+	})
+	if len(sizes) != 16 || !slices.IsSorted(sizes) {
+		t.Fatalf("expected 16 subsets smallest first, got sizes %v", sizes)
+	}
+	calls := 0
+	set.EachSubset(s, func(subset set.Set[int]) bool {
+		calls += 1
+		return calls < 3
+	})
+	if calls != 3 {
+		t.Fatalf("expected enumeration to stop after 3 subsets, got %d", calls)
+	}
+}
+
+func TestFrozen(t *testing.T) {
+	s := set.NewSet(1, 2)
+	f := set.Freeze(s)
+	s.Add(3)
+	if f.Size() != 2 || f.Has(3) {
+		t.Fatalf("expected frozen set to ignore changes to its source, got %s", f)
+	}
+	thawed := f.Thaw()
+	thawed.Remove(1)
+	if !f.Has(1) || !f.IsEqual(set.FrozenOf(2, 1)) {
+		t.Fatalf("expected frozen set to ignore changes to its copies, got %s", f)
+	}
+	var empty set.Frozen[int]
+	if empty.Size() != 0 || empty.Has(1) || !empty.IsEqual(set.FrozenOf[int]()) {
+		t.Fatal("expected the zero frozen set to be empty")
+	}
+}
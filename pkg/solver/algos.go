@@ -10,105 +10,104 @@ import (
 
 const debug = false
 
-func hitMine(x, y int) {
-	panic(fmt.Sprintf("solver hit mine at (%d, %d)\n", x, y))
-}
-
-// If the game is fresh, reveal a random tile.
-// Returns true if action was taken.
-func revealIfFresh(b *board.Board) bool {
-	if !b.HasReveals() {
-		if b.Reveal(0, 0) {
-			hitMine(0, 0)
-		}
-		return true
+// If the game is fresh, propose revealing a corner.
+func revealIfFresh(v board.View) []board.Move {
+	if !v.HasReveals() {
+		return []board.Move{{
+			Kind:   board.MoveReveal,
+			Tile:   util.Vec{X: 0, Y: 0},
+			Reason: "no tiles revealed yet",
+		}}
 	}
-	return false
+	return nil
 }
 
 // Find any tiles that are obviously a mine.
-// Returns true if action was taken.
-func findObviousMines(b *board.Board) bool {
-	size := b.GetSize()
+func findObviousMines(v board.View) []board.Move {
+	width, height := v.GetWidth(), v.GetHeight()
 
-	findDefiniteFlags := func(x, y int) bool {
-		numNeighbors := b.GetNumNeighbors(x, y)
-		if numNeighbors == 0 {
-			return false
+	findDefiniteFlags := func(x, y int) []board.Move {
+		numNeighbors := v.GetNumNeighbors(x, y)
+		if numNeighbors <= 0 {
+			return nil
 		}
 		numUnrevealedNeighbors := 0
 		numUnrevealedUnflaggedNeighbors := 0
-		neighbors := util.GetNeighbors(x, y, size)
+		neighbors := util.GetNeighbors(x, y, width, height)
 		for _, neighbor := range neighbors {
-			if !b.Revealed(neighbor.X, neighbor.Y) {
+			if !v.Revealed(neighbor.X, neighbor.Y) {
 				numUnrevealedNeighbors += 1
-				if !b.HasFlag(neighbor.X, neighbor.Y) {
+				if !v.HasFlag(neighbor.X, neighbor.Y) {
 					numUnrevealedUnflaggedNeighbors += 1
 				}
 			}
 		}
+		out := []board.Move{}
 		if numUnrevealedNeighbors == numNeighbors &&
 			numUnrevealedUnflaggedNeighbors > 0 {
 			for _, neighbor := range neighbors {
-				if !b.Revealed(neighbor.X, neighbor.Y) &&
-					!b.HasFlag(neighbor.X, neighbor.Y) {
+				if !v.Revealed(neighbor.X, neighbor.Y) &&
+					!v.HasFlag(neighbor.X, neighbor.Y) {
 					if debug {
 						fmt.Printf("solver flagging (%d, %d)\n", neighbor.X, neighbor.Y)
 					}
-					b.Flag(neighbor.X, neighbor.Y, true)
-					return true
+					out = append(out, board.Move{
+						Kind: board.MoveFlag,
+						Tile: neighbor,
+						Reason: fmt.Sprintf(
+							"(%d, %d) shows %d with %d unrevealed neighbors",
+							x, y, numNeighbors, numUnrevealedNeighbors,
+						),
+					})
 				}
 			}
 		}
-		return false
+		return out
 	}
-	return forEachRevealed(b, findDefiniteFlags)
+	return forEachRevealed(v, findDefiniteFlags)
 }
 
-// Find any tiles that are obviously empty.
-// Returns true if action was taken.
-func findObiousEmpty(b *board.Board) bool {
-	size := b.GetSize()
+// Find any numbers whose mines are all flagged, so their neighbors can be cleared.
+func findObiousEmpty(v board.View) []board.Move {
+	width, height := v.GetWidth(), v.GetHeight()
 
-	findDefiniteEmpty := func(x, y int) bool {
-		numNeighbors := b.GetNumNeighbors(x, y)
-		neighbors := util.GetNeighbors(x, y, size)
+	findDefiniteEmpty := func(x, y int) []board.Move {
+		numNeighbors := v.GetNumNeighbors(x, y)
+		neighbors := util.GetNeighbors(x, y, width, height)
 		numFlaggedNeighbors := 0
 		numUnrevealedNeighbors := 0
 		for _, neighbor := range neighbors {
-			if b.HasFlag(neighbor.X, neighbor.Y) {
+			if v.HasFlag(neighbor.X, neighbor.Y) {
 				numFlaggedNeighbors += 1
 			}
-			if !b.Revealed(neighbor.X, neighbor.Y) {
+			if !v.Revealed(neighbor.X, neighbor.Y) {
 				numUnrevealedNeighbors += 1
 			}
 		}
 		if numNeighbors == numFlaggedNeighbors &&
 			numUnrevealedNeighbors > numFlaggedNeighbors {
-			for _, neighbor := range neighbors {
-				if !b.Revealed(neighbor.X, neighbor.Y) &&
-					!b.HasFlag(neighbor.X, neighbor.Y) {
-					if debug {
-						fmt.Printf(
-							"solver revealing (%d, %d)\n", neighbor.X, neighbor.Y,
-						)
-					}
-					if b.Reveal(neighbor.X, neighbor.Y) {
-						hitMine(neighbor.X, neighbor.Y)
-					}
-					return true
-				}
+			// Clear the whole neighborhood in one move.
+			if debug {
+				fmt.Printf("solver chording (%d, %d)\n", x, y)
 			}
+			return []board.Move{{
+				Kind: board.MoveChord,
+				Tile: util.Vec{X: x, Y: y},
+				Reason: fmt.Sprintf(
+					"(%d, %d) shows %d with %d flagged neighbors",
+					x, y, numNeighbors, numFlaggedNeighbors,
+				),
+			}}
 		}
-		return false
+		return nil
 	}
-	return forEachRevealed(b, findDefiniteEmpty)
+	return forEachRevealed(v, findDefiniteEmpty)
 }
 
 // The fact that the given set contains the given number of mines.
 type Fact struct {
 	mines int
-	tiles set.Set[util.Vec]
+	tiles set.Bits
 }
 
 func (f *Fact) String() string {
@@ -117,9 +116,6 @@ func (f *Fact) String() string {
 
 // Knowledge graph.
 type Knowledge struct {
-	// A ref to the board (to take actions on)
-	b *board.Board
-
 	// The full set of all current facts
 	nodes []*Fact
 
@@ -130,11 +126,10 @@ type Knowledge struct {
 	unchecked [][]*Fact
 }
 
-func NewKnowledge(b *board.Board) *Knowledge {
+func NewKnowledge(v board.View) *Knowledge {
 	return &Knowledge{
-		b:     b,
 		nodes: make([]*Fact, 0),
-		tiles: util.DArray[[]*Fact](b.GetSize(), func() []*Fact {
+		tiles: util.DArray[[]*Fact](v.GetWidth(), v.GetHeight(), func() []*Fact {
 			return make([]*Fact, 0)
 		}),
 		unchecked: make([][]*Fact, 0),
@@ -142,16 +137,16 @@ func NewKnowledge(b *board.Board) *Knowledge {
 }
 
 // Whether the given number of mines / tiles is already known.
-func (k *Knowledge) HasFact(mines int, vecs set.Set[util.Vec]) bool {
+func (k *Knowledge) HasFact(mines int, vecs set.Bits) bool {
 	for _, node := range k.nodes {
-		if node.mines == mines && set.IsEqual(node.tiles, vecs) {
+		if node.mines == mines && node.tiles.IsEqual(vecs) {
 			return true
 		}
 	}
 	return false
 }
 
-func (k *Knowledge) AddFact(mines int, vecs set.Set[util.Vec]) {
+func (k *Knowledge) AddFact(mines int, vecs set.Bits) {
 	if k.HasFact(mines, vecs) {
 		return
 	}
@@ -177,50 +172,68 @@ func (k *Knowledge) HasUncheckedDeductions() bool {
 }
 
 // Run the next deduction from the queue.
-// Returns true if an action was taken.
-func (k *Knowledge) RunNextDeduction() bool {
+// Returns any moves proven by the deduction.
+func (k *Knowledge) RunNextDeduction() []board.Move {
 	if len(k.unchecked) == 0 {
-		return false
+		return nil
 	}
 	next := k.unchecked[0]
 	k.unchecked = k.unchecked[1:]
-	if len(next) == 2 {
-		return k.RunDualDeduction(next[0], next[1]) ||
-			k.RunDualDeduction(next[1], next[0])
-	} else {
-		// TODO: break up into pairs, or n-deduction (if not single fact)
-		fmt.Printf("cannot run deduction on %d facts\n", len(next))
-		return false
+	// Knowledge only deduces from pairs, so a larger group runs each of its pairs.
+	for i := 0; i < len(next); i++ {
+		for j := i + 1; j < len(next); j++ {
+			if moves := k.RunDualDeduction(next[i], next[j]); len(moves) > 0 {
+				return moves
+			}
+			if moves := k.RunDualDeduction(next[j], next[i]); len(moves) > 0 {
+				return moves
+			}
+		}
 	}
+	return nil
 }
 
 // Run a deduction on a pair of facts.
-// Returns true if an action was taken.
-func (k *Knowledge) RunDualDeduction(a, b *Fact) bool {
-	if set.IsEqual(a.tiles, b.tiles) && a.mines != b.mines {
+// Returns any moves proven by the deduction.
+func (k *Knowledge) RunDualDeduction(a, b *Fact) []board.Move {
+	if a.tiles.IsEqual(b.tiles) && a.mines != b.mines {
 		panic(fmt.Sprintf("contradiction between %s & %s", a, b))
 	}
-	if set.IsSubsetStrict(a.tiles, b.tiles) {
+	if a.tiles.IsSubsetStrict(b.tiles) {
 		if b.mines == a.mines {
 			// One-step deduction solves 21.5% of 8x8 w/ 10 mines
 			// Tiles are clearable!
-			empty := set.Sub(a.tiles, b.tiles).AsList()
+			empty := a.tiles.Sub(b.tiles).AsList()
 			if len(empty) == 0 {
 				panic(fmt.Sprintf("expected empty tiles from %s - %s", a, b))
 			}
-			if k.b.Reveal(empty[0].X, empty[0].Y) {
-				hitMine(empty[0].X, empty[0].Y)
+			out := make([]board.Move, len(empty))
+			for i, tile := range empty {
+				out[i] = board.Move{
+					Kind:   board.MoveReveal,
+					Tile:   tile,
+					Reason: fmt.Sprintf("%s minus %s leaves no mines", a, b),
+				}
 			}
-			return true
+			return out
 		} else if b.mines < a.mines {
 			// Multi-step deduction solves 30% of 8x8 w/ 10 mines
 			subZoneMines := a.mines - b.mines
-			subZoneTiles := set.Sub(a.tiles, b.tiles)
-			if subZoneMines > 0 && subZoneMines == len(subZoneTiles) {
+			subZoneTiles := a.tiles.Sub(b.tiles)
+			if subZoneMines > 0 && subZoneMines == subZoneTiles.Size() {
 				// Definite mines!
-				tile := subZoneTiles.AsList()[0]
-				k.b.Flag(tile.X, tile.Y, true)
-				return true
+				out := make([]board.Move, 0, subZoneMines)
+				for _, tile := range subZoneTiles.AsList() {
+					out = append(out, board.Move{
+						Kind: board.MoveFlag,
+						Tile: tile,
+						Reason: fmt.Sprintf(
+							"%s minus %s leaves %d of its %d mines in %d tiles",
+							a, b, subZoneMines, a.mines, subZoneTiles.Size(),
+						),
+					})
+				}
+				return out
 			}
 			if debug {
 				fmt.Println("from deduction")
@@ -231,41 +244,42 @@ func (k *Knowledge) RunDualDeduction(a, b *Fact) bool {
 			)
 		}
 	}
-	return false
+	return nil
 }
 
 // Accumulate facts about the state of the board and deduce.
-// Returns true if action was taken.
-func deduce(b *board.Board) bool {
+// Returns any moves proven by the first successful deduction, and the number of
+// deductions run.
+func deduce(v board.View) ([]board.Move, int) {
 	maxDeductions := 100000
 
-	size := b.GetSize()
-	know := NewKnowledge(b)
+	width, height := v.GetWidth(), v.GetHeight()
+	know := NewKnowledge(v)
 
-	unrevealedNeighbors := func(x, y int) set.Set[util.Vec] {
-		out := make([]util.Vec, 0)
-		for _, neighbor := range util.GetNeighbors(x, y, size) {
-			if !b.Revealed(neighbor.X, neighbor.Y) {
-				out = append(out, neighbor)
+	unrevealedNeighbors := func(x, y int) set.Bits {
+		out := set.NewBits(width)
+		for _, neighbor := range util.GetNeighbors(x, y, width, height) {
+			if !v.Revealed(neighbor.X, neighbor.Y) {
+				out.Add(neighbor)
 			}
 		}
-		return set.FromList(out)
+		return out
 	}
 
-	flaggedNeighbors := func(x, y int) set.Set[util.Vec] {
-		out := make([]util.Vec, 0)
-		for _, neighbor := range util.GetNeighbors(x, y, size) {
-			if b.HasFlag(neighbor.X, neighbor.Y) {
-				out = append(out, neighbor)
+	flaggedNeighbors := func(x, y int) set.Bits {
+		out := set.NewBits(width)
+		for _, neighbor := range util.GetNeighbors(x, y, width, height) {
+			if v.HasFlag(neighbor.X, neighbor.Y) {
+				out.Add(neighbor)
 			}
 		}
-		return set.FromList(out)
+		return out
 	}
 
 	// Accumulate a fact for each visible number.
-	for y := 0; y < size; y++ {
-		for x := 0; x < size; x++ {
-			if b.Revealed(x, y) && b.GetNumNeighbors(x, y) > 0 {
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if v.Revealed(x, y) && v.GetNumNeighbors(x, y) > 0 {
 				unrevealed := unrevealedNeighbors(x, y)
 				flagged := flaggedNeighbors(x, y)
 				if unrevealed.Size() > flagged.Size() {
@@ -273,8 +287,8 @@ func deduce(b *board.Board) bool {
 						fmt.Println("from board")
 					}
 					know.AddFact(
-						b.GetNumNeighbors(x, y)-flagged.Size(),
-						set.Sub(unrevealedNeighbors(x, y), flagged),
+						v.GetNumNeighbors(x, y)-flagged.Size(),
+						unrevealed.Sub(flagged),
 					)
 				}
 			}
@@ -284,14 +298,14 @@ func deduce(b *board.Board) bool {
 	// Continuously attempt deductions
 	for i := 0; i < maxDeductions; i++ {
 		if !know.HasUncheckedDeductions() {
-			return false
+			return nil, i
 		}
-		if know.RunNextDeduction() {
-			return true
+		if moves := know.RunNextDeduction(); len(moves) > 0 {
+			return moves, i + 1
 		}
 	}
 
 	fmt.Println("executed max deductions")
 
-	return false
+	return nil, maxDeductions
 }
@@ -4,26 +4,37 @@ import (
 	"github.com/levilutz/minesweeper/pkg/board"
 )
 
-// Compute until a single command is run (either a flag or a reveal).
-// Returns whether something could be run, false if stuck.
-func Pass(b *board.Board) bool {
-	if revealIfFresh(b) {
-		return true
-	}
+// Propose moves that are certain to be correct, ranked by the simplest rule
+// that proves them. Returns no moves if stuck. Does not modify the board.
+func Moves(v board.View) []board.Move {
+	moves, _ := Analyze(v)
+	return moves
+}
 
-	if findObviousMines(b) {
-		return true
+// Like Moves, but also returns the number of deductions run.
+func Analyze(v board.View) ([]board.Move, int) {
+	if moves := revealIfFresh(v); len(moves) > 0 {
+		return moves, 0
 	}
 
-	if findObiousEmpty(b) {
-		return true
+	obvious := append(findObviousMines(v), findObiousEmpty(v)...)
+	if len(obvious) > 0 {
+		return obvious, 0
 	}
 
 	// At this point, solves 13% of 8x8 w/ 10 mines
 
-	if deduce(b) {
-		return true
-	}
+	return deduce(v)
+}
 
-	return false
+// Compute until a single command is run (either a flag or a reveal), using an
+// actor such as the board's PlayerActor.
+// Returns whether something could be run, false if stuck.
+func Pass(v board.View, a board.Actor) bool {
+	moves := Moves(v)
+	if len(moves) == 0 {
+		return false
+	}
+	board.Apply(a, moves[0])
+	return true
 }
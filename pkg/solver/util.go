@@ -1,20 +1,27 @@
 package solver
 
-import "github.com/levilutz/minesweeper/pkg/board"
+import (
+	"github.com/levilutz/minesweeper/pkg/board"
+	"github.com/levilutz/minesweeper/pkg/set"
+	"github.com/levilutz/minesweeper/pkg/util"
+)
 
-// Run the given function for each revealed tile.
-// When the passed-in function returns true, the top level function quits with true.
-func forEachRevealed(b *board.Board, fn func(x, y int) bool) bool {
-	size := b.GetSize()
-	for y := 0; y < size; y++ {
-		for x := 0; x < size; x++ {
-			if b.Revealed(x, y) {
-				out := fn(x, y)
-				if out {
-					return true
+// Run the given function for each revealed tile, collecting the moves returned.
+// Moves on a tile already proposed are skipped.
+func forEachRevealed(v board.View, fn func(x, y int) []board.Move) []board.Move {
+	out := []board.Move{}
+	seen := set.NewSet[util.Vec]()
+	for y := 0; y < v.GetHeight(); y++ {
+		for x := 0; x < v.GetWidth(); x++ {
+			if v.Revealed(x, y) {
+				for _, move := range fn(x, y) {
+					if !seen.Has(move.Tile) {
+						seen[move.Tile] = struct{}{}
+						out = append(out, move)
+					}
 				}
 			}
 		}
 	}
-	return false
+	return out
 }
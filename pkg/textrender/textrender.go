@@ -3,6 +3,7 @@ package textrender
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/levilutz/minesweeper/pkg/board"
 	"github.com/levilutz/minesweeper/pkg/util/bashcolor"
@@ -49,22 +50,37 @@ func renderTile(b *board.Board, x, y int) string {
 	}
 }
 
+// Get the number of hex digits needed to label coordinates below n.
+func labelDigits(n int) int {
+	return len(strconv.FormatInt(int64(max(n-1, 0)), 16))
+}
+
 func RenderBoard(b *board.Board) string {
+	yDigits := labelDigits(b.GetHeight())
+	xDigits := labelDigits(b.GetWidth())
 	out := "\n"
-	for y := b.GetSize() - 1; y >= 0; y-- {
-		out += fmt.Sprintf("%x | ", y)
-		for x := 0; x < b.GetSize(); x++ {
+	for y := b.GetHeight() - 1; y >= 0; y-- {
+		out += fmt.Sprintf("%*x | ", yDigits, y)
+		for x := 0; x < b.GetWidth(); x++ {
 			out += " " + renderTile(b, x, y)
 		}
 		out += "\n"
 	}
-	out += "--+-"
-	for x := 0; x < b.GetSize(); x++ {
+	out += strings.Repeat("-", yDigits+1) + "+-"
+	for x := 0; x < b.GetWidth(); x++ {
 		out += "--"
 	}
-	out += "\n  | "
-	for x := 0; x < b.GetSize(); x++ {
-		out += fmt.Sprintf(" %x", x)
+	// X labels are written vertically when wider than a single digit.
+	for d := xDigits - 1; d >= 0; d-- {
+		out += "\n" + strings.Repeat(" ", yDigits) + " | "
+		for x := 0; x < b.GetWidth(); x++ {
+			label := strconv.FormatInt(int64(x), 16)
+			if d < len(label) {
+				out += " " + string(label[len(label)-1-d])
+			} else {
+				out += "  "
+			}
+		}
 	}
 	out += "\n"
 
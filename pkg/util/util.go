@@ -1,6 +1,7 @@
 package util
 
 import (
+	"cmp"
 	"fmt"
 	"math/rand"
 )
@@ -31,12 +32,12 @@ func Map[T, U any](l []T, fn func(T) U) []U {
 	return out
 }
 
-// Return a randomly shuffled permutation of a given array.
+// Return a randomly shuffled permutation of a given array, using the given source.
 // Durstenfeld algorithm - O(n).
-func Shuffle[T any](l []T) []T {
+func Shuffle[T any](l []T, rng *rand.Rand) []T {
 	l = ListCopy(l)
 	for i := 0; i < len(l)-1; i++ {
-		j := rand.Intn(len(l)-i) + i
+		j := rng.Intn(len(l)-i) + i
 		l[i], l[j] = l[j], l[i]
 	}
 	return l
@@ -59,15 +60,15 @@ func Flatten[T any](items [][]T) []T {
 	return out
 }
 
-// Generate a square double array of the given type.
+// Generate a double array of the given type, indexed [x][y].
 // If defaultValueFactor is provided, it is run to generate default
 // values for each grid item.
-func DArray[T any](l int, defaultValueFactory ...func() T) [][]T {
-	out := make([][]T, l)
-	for x := 0; x < l; x++ {
-		out[x] = make([]T, l)
+func DArray[T any](width, height int, defaultValueFactory ...func() T) [][]T {
+	out := make([][]T, width)
+	for x := 0; x < width; x++ {
+		out[x] = make([]T, height)
 		if len(defaultValueFactory) > 0 {
-			for y := 0; y < l; y++ {
+			for y := 0; y < height; y++ {
 				out[x][y] = defaultValueFactory[0]()
 			}
 		}
@@ -93,8 +94,17 @@ func (v Vec) String() string {
 	return fmt.Sprintf("<%d, %d>", v.X, v.Y)
 }
 
+// Order vectors by row, then column. Returns -1, 0 or 1 as v is before, equal
+// to or after o.
+func (v Vec) Compare(o Vec) int {
+	if v.Y != o.Y {
+		return cmp.Compare(v.Y, o.Y)
+	}
+	return cmp.Compare(v.X, o.X)
+}
+
 // Get neighbors in a grid of the given coordinates.
-func GetNeighbors(x, y, size int) []Vec {
+func GetNeighbors(x, y, width, height int) []Vec {
 	loX := x
 	if loX > 0 {
 		loX -= 1
@@ -104,11 +114,11 @@ func GetNeighbors(x, y, size int) []Vec {
 		loY -= 1
 	}
 	hiX := x
-	if hiX < size-1 {
+	if hiX < width-1 {
 		hiX += 1
 	}
 	hiY := y
-	if hiY < size-1 {
+	if hiY < height-1 {
 		hiY += 1
 	}
 	out := make([]Vec, (hiX-loX+1)*(hiY-loY+1)-1)
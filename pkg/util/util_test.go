@@ -2,6 +2,7 @@ package util_test
 
 import (
 	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/levilutz/minesweeper/pkg/util"
@@ -12,13 +13,14 @@ func TestShuffle(t *testing.T) {
 	rounds := 100000
 	printOutput := true
 
+	rng := rand.New(rand.NewSource(1))
 	freq := make([][]int, tblSize)
 	for i := 0; i < tblSize; i++ {
 		freq[i] = make([]int, tblSize)
 	}
 	for r := 0; r < rounds; r++ {
 		arr := util.IndexList(tblSize)
-		arr = util.Shuffle(arr)
+		arr = util.Shuffle(arr, rng)
 		for i := 0; i < tblSize; i++ {
 			freq[i][arr[i]] += 1
 		}
@@ -48,3 +50,13 @@ func TestShuffle(t *testing.T) {
 		t.Fatalf("got excessive deviations from even distribution: %d", deviations)
 	}
 }
+
+func TestShuffleSeeded(t *testing.T) {
+	a := util.Shuffle(util.IndexList(100), rand.New(rand.NewSource(42)))
+	b := util.Shuffle(util.IndexList(100), rand.New(rand.NewSource(42)))
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("expected equal shuffles from equal seeds, differ at %d", i)
+		}
+	}
+}